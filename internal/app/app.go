@@ -2,92 +2,328 @@ package app
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	handlerAudio "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/audio"
 	handlerCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/captcha"
+	handlerLiveness "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/liveness"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/middleware"
 	handlerPow "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/pow"
+	handlerReadiness "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/readiness"
+	handlerRefresh "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/refresh"
+	handlerSiteverify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/siteverify"
 	handlerVerify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/handler/verify"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/keyring"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/lifecycle"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	processAudio "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/audio"
+	tasksAudio "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/audio/task"
 	processCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha"
 	tasksCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
 	processPow "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/pow"
 	tasksPow "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/pow/task"
+	processReadiness "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/readiness"
+	tasksReadiness "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/readiness/task"
+	processRefresh "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/refresh"
+	tasksRefresh "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/refresh/task"
+	tasksRisk "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/risk/task"
+	processSiteverify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/siteverify"
+	tasksSiteverify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/siteverify/task"
 	processVerify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/verify"
 	tasksVerify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/verify/task"
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/registry"
+	serviceMetrics "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/metrics"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/ratelimit"
 	serviceRedis "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/redis"
+	serviceTracing "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/tracing"
 )
 
 type App struct {
-	httpServer *http.Server
+	httpServer    *http.Server
+	metricsServer *http.Server
+	tracing       *serviceTracing.Provider
+	lifecycle     *lifecycle.State
+	drainDelay    time.Duration
 }
 
 func Build(cfg *registry.Config) (*App, error) {
+	tracingProvider, err := serviceTracing.New(context.Background(), serviceTracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: cfg.Tracing.ServiceName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	maxRetries := cfg.Infrastructure.Retry.MaxAttempts
-	retryDelay := cfg.Infrastructure.Retry.DelaySeconds
-	var err error
+	retryDelay := cfg.Infrastructure.Retry.Delay
 
 	var redisClient serviceRedis.Client
 	for i := 0; i < maxRetries; i++ {
 		redisClient, err = serviceRedis.NewClient(cfg.Redis.URL)
 		if err == nil {
 			if err = redisClient.Ping(context.Background()); err == nil {
-				log.Println("INFO: successfully connected to Redis")
+				slog.Info("connected to redis")
 				break
 			}
 		}
-		log.Printf("INFO: could not connect to Redis, retrying in %v... (%d/%d)", retryDelay, i+1, maxRetries)
+		slog.Warn("could not connect to redis, retrying", "error", err, "delay", retryDelay.String(), "attempt", i+1, "maxAttempts", maxRetries)
 		time.Sleep(retryDelay)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	createSignedSeedTask := tasksPow.NewCreateSignedSeedTask(cfg.Security.HmacSecret)
-	powProcess := processPow.NewProcess(createSignedSeedTask)
-	powHandler := handlerPow.NewHandler(powProcess)
+	appMetrics := serviceMetrics.New()
+	redisClient = serviceRedis.NewInstrumentedClient(redisClient, appMetrics)
 
-	validateSignatureTask := tasksCaptcha.NewValidateSignatureTask(cfg.Security.HmacSecret)
+	baseDifficulty, maxDifficulty := cfg.PowDifficulty()
+	chooseDifficultyTask := tasksPow.NewChooseDifficultyTask(redisClient, tasksPow.DifficultyPolicy{
+		Mode:            cfg.Security.DifficultyMode,
+		Base:            baseDifficulty,
+		Max:             maxDifficulty,
+		Window:          time.Duration(cfg.Security.Adaptive.WindowSeconds) * time.Second,
+		RequestsPerStep: cfg.Security.Adaptive.RequestsPerStep,
+	})
+	powAlgorithm, err := pow.NewAlgorithm(
+		cfg.Security.Algorithm.Name,
+		pow.Argon2id{
+			Time:      cfg.Security.Algorithm.Argon2id.Time,
+			MemoryKiB: cfg.Security.Algorithm.Argon2id.MemoryKiB,
+			Threads:   cfg.Security.Algorithm.Argon2id.Threads,
+		},
+		pow.Scrypt{
+			N: cfg.Security.Algorithm.Scrypt.N,
+			R: cfg.Security.Algorithm.Scrypt.R,
+			P: cfg.Security.Algorithm.Scrypt.P,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	clientResolver, err := client.NewResolver(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	seedBinding, err := client.NewBinding(cfg.Security.Binding.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	riskPolicy, err := newRiskPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	assessRiskTask := tasksRisk.NewAssessRiskTask(redisClient, riskPolicy)
+	recordRiskTask := tasksRisk.NewRecordRiskTask(redisClient, riskPolicy)
+
+	seedKeys, err := newSeedKeyring(cfg)
+	if err != nil {
+		return nil, err
+	}
+	createSignedSeedTask := tasksPow.NewCreateSignedSeedTask(seedKeys, seedBinding, cfg.Security.Audience, cfg.Security.TtlMinutes)
+	powProcess := processPow.NewProcess(chooseDifficultyTask, assessRiskTask, createSignedSeedTask, powAlgorithm)
+	powHandler := handlerPow.NewHandler(powProcess, clientResolver)
+
+	validateSignatureTask := tasksCaptcha.NewValidateSignatureTask(seedKeys)
 	checkSeedTimestampTask := tasksCaptcha.NewCheckSeedTimestampTask(cfg.Security.TtlMinutes)
-	validateUsedSeedTask := tasksCaptcha.NewValidateUsedSeedTask(redisClient)
-	verifyPowTask := tasksCaptcha.NewVerifyPowTask(cfg.Security.Difficulty)
+	checkSeedAudienceTask := tasksCaptcha.NewCheckSeedAudienceTask(cfg.Security.Audience)
+	checkSeedClientTask := tasksCaptcha.NewCheckSeedClientTask(seedBinding)
+	verifyPowTask := tasksCaptcha.NewVerifyPowTask(pow.Difficulty{Value: cfg.Security.Difficulty, Mode: cfg.Security.DifficultyMode}, appMetrics)
 	marksSeedUsedTask := tasksCaptcha.NewMarkSeedUsedTask(redisClient, cfg.Captcha.TtlMinutes)
-	generateCaptchaTask := tasksCaptcha.NewGenerateCaptchaTask()
-	saveCaptchaTask := tasksCaptcha.NewSaveCaptchaTask(redisClient, cfg.Captcha.TtlMinutes, cfg.Captcha.MaxTries)
-	captchaProcess := processCaptcha.NewProcess(validateSignatureTask, checkSeedTimestampTask, validateUsedSeedTask, verifyPowTask, marksSeedUsedTask, generateCaptchaTask, saveCaptchaTask)
-	captchaHandler := handlerCaptcha.NewHandler(captchaProcess)
+	generateCaptchaTask, err := tasksCaptcha.NewGenerateCaptchaTask(captchaDriverOptions(cfg.Captcha.Driver), appMetrics)
+	if err != nil {
+		return nil, err
+	}
+	hardCaptchaTask := generateCaptchaTask
+	if cfg.Escalation.HardDriver.Type != "" {
+		hardCaptchaTask, err = tasksCaptcha.NewGenerateCaptchaTask(captchaDriverOptions(cfg.Escalation.HardDriver), appMetrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+	saveCaptchaTask := tasksCaptcha.NewSaveCaptchaTask(redisClient, cfg.Captcha.TtlMinutes, cfg.Captcha.MaxTries, appMetrics)
+	chooseModeTask := tasksCaptcha.NewChooseModeTask(cfg.Captcha.InvisibleSites)
+	saveSolvedCaptchaTask := tasksCaptcha.NewSaveSolvedCaptchaTask(redisClient, cfg.Captcha.TtlMinutes, appMetrics)
+	issueTokenTask := tasksVerify.NewIssueTokenTask(cfg.Token.Secret, cfg.Token.Audience, cfg.Token.TtlSeconds)
+	captchaProcess := processCaptcha.NewProcess(validateSignatureTask, checkSeedTimestampTask, checkSeedAudienceTask, checkSeedClientTask, verifyPowTask, marksSeedUsedTask, assessRiskTask, recordRiskTask, chooseModeTask, saveSolvedCaptchaTask, issueTokenTask, generateCaptchaTask, hardCaptchaTask, saveCaptchaTask)
+	captchaHandler := handlerCaptcha.NewHandler(captchaProcess, clientResolver)
+
+	checkRefreshableCaptchaTask := tasksRefresh.NewCheckRefreshableCaptchaTask(redisClient, cfg.Captcha.MaxRefreshes)
+	invalidateCaptchaTask := tasksRefresh.NewInvalidateCaptchaTask(redisClient, cfg.Captcha.MaxRefreshes)
+	saveRefreshedCaptchaTask := tasksRefresh.NewSaveRefreshedCaptchaTask(redisClient)
+	refreshProcess := processRefresh.NewProcess(checkRefreshableCaptchaTask, invalidateCaptchaTask, assessRiskTask, generateCaptchaTask, hardCaptchaTask, saveRefreshedCaptchaTask)
+	refreshHandler := handlerRefresh.NewHandler(refreshProcess, clientResolver)
+
+	prepareAudioAnswerTask := tasksAudio.NewPrepareAudioAnswerTask(redisClient, cfg.Captcha.Audio.Length)
+	renderAudioTask := tasksAudio.NewRenderAudioTask(cfg.Captcha.Audio.Language)
+	audioProcess := processAudio.NewProcess(prepareAudioAnswerTask, renderAudioTask)
+	audioHandler := handlerAudio.NewHandler(audioProcess)
+
+	validateCaptchaTask := tasksVerify.NewValidateCaptchaTask(redisClient, cfg.Captcha.TtlMinutes, cfg.Captcha.ConsumeOnSolve, appMetrics)
+	verifyProcess := processVerify.NewProcess(validateCaptchaTask, recordRiskTask, issueTokenTask)
+	verifyHandler := handlerVerify.NewHandler(verifyProcess, clientResolver)
+
+	siteverifySecrets := make(map[string]string, len(cfg.Siteverify.Secrets))
+	for _, s := range cfg.Siteverify.Secrets {
+		siteverifySecrets[s.Site] = s.Secret
+	}
+	authenticateServiceTask := tasksSiteverify.NewAuthenticateServiceTask(siteverifySecrets)
+	redeemCaptchaTask := tasksSiteverify.NewRedeemCaptchaTask(redisClient, cfg.Captcha.InvisibleSites)
+	siteverifyProcess := processSiteverify.NewProcess(authenticateServiceTask, redeemCaptchaTask)
+	siteverifyHandler := handlerSiteverify.NewHandler(siteverifyProcess)
+
+	rateLimitAllowlist, err := client.ParseNetworks(cfg.RateLimit.Allowlist)
+	if err != nil {
+		return nil, err
+	}
+	rateLimit := middleware.NewRateLimit(
+		ratelimit.NewFallback(ratelimit.NewRedis(redisClient), ratelimit.NewMemory()),
+		clientResolver,
+		rateLimitAllowlist,
+	)
 
-	fetchCaptchaTask := tasksVerify.NewFetchCaptchaTask(redisClient)
-	validateCaptchaTask := tasksVerify.NewValidateCaptchaTask(redisClient, cfg.Captcha.TtlMinutes)
-	verifyProcess := processVerify.NewProcess(fetchCaptchaTask, validateCaptchaTask)
-	verifyHandler := handlerVerify.NewHandler(verifyProcess)
+	lifecycleState := lifecycle.NewState()
+	readinessProcess := processReadiness.NewProcess(
+		tasksReadiness.NewCheckDrainingTask(lifecycleState),
+		tasksReadiness.NewCheckConfigTask(cfg),
+		tasksReadiness.NewPingRedisTask(redisClient, cfg.Health.RedisTimeout),
+	)
+	readinessHandler := handlerReadiness.NewHandler(readinessProcess)
+	livenessHandler := handlerLiveness.NewHandler()
+
+	trustedProxies, err := client.ParseNetworks(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	instrument := middleware.NewMetrics(appMetrics)
+
+	trace := middleware.NewTrace(trustedProxies)
+
+	route := func(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+		return trace.Wrap(instrument.Wrap(endpoint, next))
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/pow", powHandler.Handle)
-	mux.HandleFunc("/captcha", captchaHandler.Handle)
-	mux.HandleFunc("/verify", verifyHandler.Handle)
+	mux.HandleFunc("/pow", route("pow", rateLimit.Wrap("pow", rateLimitRule(cfg.RateLimit.Pow), powHandler.Handle)))
+	mux.HandleFunc("/captcha", route("captcha", rateLimit.Wrap("captcha", rateLimitRule(cfg.RateLimit.Captcha), captchaHandler.Handle)))
+	mux.HandleFunc("/captcha/refresh", route("captcha_refresh", rateLimit.Wrap("refresh", rateLimitRule(cfg.RateLimit.Refresh), refreshHandler.Handle)))
+	mux.HandleFunc("/captcha/audio", route("captcha_audio", rateLimit.Wrap("audio", rateLimitRule(cfg.RateLimit.Audio), audioHandler.Handle)))
+	mux.HandleFunc("/verify", route("verify", rateLimit.Wrap("verify", rateLimitRule(cfg.RateLimit.Verify), verifyHandler.Handle)))
+	mux.HandleFunc("/siteverify", route("siteverify", rateLimit.Wrap("siteverify", rateLimitRule(cfg.RateLimit.Siteverify), siteverifyHandler.Handle)))
+	mux.HandleFunc("/healthz", livenessHandler.Handle)
+	mux.HandleFunc("/readyz", readinessHandler.Handle)
 
 	httpServer := &http.Server{
 		Addr: ":" + cfg.Server.HTTPPort,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Handler: middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			r.URL.Path = strings.TrimSuffix(r.URL.Path, "/")
 			mux.ServeHTTP(w, r)
-		}),
+		})),
+	}
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", appMetrics.Handler())
+	metricsServer := &http.Server{
+		Addr:    ":" + cfg.Server.MetricsPort,
+		Handler: metricsMux,
 	}
 
 	return &App{
-		httpServer: httpServer,
+		httpServer:    httpServer,
+		metricsServer: metricsServer,
+		tracing:       tracingProvider,
+		lifecycle:     lifecycleState,
+		drainDelay:    cfg.Health.DrainDelay,
 	}, nil
 }
 
 func (a *App) RunHTTP() error {
-	log.Printf("INFO: HTTP server listening on %s", a.httpServer.Addr)
+	slog.Info("http server listening", "addr", a.httpServer.Addr)
 	return a.httpServer.ListenAndServe()
 }
 
+func (a *App) RunMetrics() error {
+	slog.Info("metrics server listening", "addr", a.metricsServer.Addr)
+	return a.metricsServer.ListenAndServe()
+}
+
 func (a *App) Shutdown(ctx context.Context) {
-	log.Println("INFO: shutting down server...")
+	a.lifecycle.Drain()
+	slog.Info("draining", "delay", a.drainDelay.String())
+	select {
+	case <-time.After(a.drainDelay):
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down server")
 	_ = a.httpServer.Shutdown(ctx)
+	_ = a.metricsServer.Shutdown(ctx)
+	if err := a.tracing.Shutdown(ctx); err != nil {
+		slog.Error("could not flush traces", "error", err)
+	}
+}
+
+func newSeedKeyring(cfg *registry.Config) (*keyring.Keyring, error) {
+	grace := time.Duration(cfg.Security.TtlMinutes) * time.Minute
+	if len(cfg.Security.Keys) == 0 {
+		return keyring.New([]keyring.Key{{Id: keyring.LegacyKeyId, Secret: cfg.Security.HmacSecret}}, keyring.LegacyKeyId, grace)
+	}
+
+	keys := make([]keyring.Key, 0, len(cfg.Security.Keys))
+	for _, k := range cfg.Security.Keys {
+		keys = append(keys, keyring.Key{Id: k.Id, Secret: k.Secret, RetiredAt: k.RetiredAt})
+	}
+
+	return keyring.New(keys, cfg.Security.ActiveKeyId, grace)
+}
+
+func newRiskPolicy(cfg *registry.Config) (risk.Policy, error) {
+	levels := make([]risk.Level, 0, len(cfg.Escalation.Levels))
+	for _, l := range cfg.Escalation.Levels {
+		levels = append(levels, risk.Level{
+			Score:           l.Score,
+			ExtraDifficulty: l.ExtraDifficulty,
+			SeedTtl:         time.Duration(l.SeedTtlSeconds) * time.Second,
+			HardCaptcha:     l.HardCaptcha,
+		})
+	}
+
+	return risk.NewPolicy(
+		time.Duration(cfg.Escalation.HalfLifeSeconds)*time.Second,
+		cfg.Escalation.IPv4PrefixBits,
+		cfg.Escalation.IPv6PrefixBits,
+		levels,
+	)
+}
+
+func captchaDriverOptions(d registry.CaptchaDriver) tasksCaptcha.DriverOptions {
+	return tasksCaptcha.DriverOptions{
+		Type:            d.Type,
+		Height:          d.Height,
+		Width:           d.Width,
+		NoiseCount:      d.NoiseCount,
+		ShowLineOptions: d.ShowLineOptions,
+		Length:          d.Length,
+		Source:          d.Source,
+		Fonts:           d.Fonts,
+		MaxSkew:         d.MaxSkew,
+		DotCount:        d.DotCount,
+		Language:        d.Language,
+	}
+}
+
+func rateLimitRule(r registry.RateLimitRule) middleware.Rule {
+	return middleware.Rule{
+		Limit:  r.Limit,
+		Window: time.Duration(r.WindowSeconds) * time.Second,
+	}
 }
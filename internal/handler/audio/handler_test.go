@@ -0,0 +1,107 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	processAudio "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/audio"
+)
+
+type mockAudioProcess struct {
+	processFunc func(ctx context.Context, req processAudio.Request) (*processAudio.Response, error)
+}
+
+func (m *mockAudioProcess) Process(ctx context.Context, req processAudio.Request) (*processAudio.Response, error) {
+	return m.processFunc(ctx, req)
+}
+
+func TestHandler_Audio(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       interface{}
+		mockFunc   func(context.Context, processAudio.Request) (*processAudio.Response, error)
+		wantStatus int
+		wantSlug   string
+	}{
+		{
+			name:   "valid request",
+			method: http.MethodPost,
+			body:   processAudio.Request{CaptchaId: "id-123", Language: "en"},
+			mockFunc: func(ctx context.Context, req processAudio.Request) (*processAudio.Response, error) {
+				return &processAudio.Response{CaptchaId: "id-123", CaptchaAudio: "audio", Language: "en"}, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			body:       nil,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantSlug:   appErrors.ErrMethodNotAllowed.Slug,
+		},
+		{
+			name:       "invalid json",
+			method:     http.MethodPost,
+			body:       "{invalid-json}",
+			wantStatus: http.StatusBadRequest,
+			wantSlug:   appErrors.ErrInvalidInput.Slug,
+		},
+		{
+			name:   "captcha already solved",
+			method: http.MethodPost,
+			body:   processAudio.Request{CaptchaId: "id-123"},
+			mockFunc: func(ctx context.Context, req processAudio.Request) (*processAudio.Response, error) {
+				return nil, appErrors.ErrCaptchaSolved
+			},
+			wantStatus: http.StatusConflict,
+			wantSlug:   appErrors.ErrCaptchaSolved.Slug,
+		},
+		{
+			name:   "process returns unknown error",
+			method: http.MethodPost,
+			body:   processAudio.Request{CaptchaId: "id-123"},
+			mockFunc: func(ctx context.Context, req processAudio.Request) (*processAudio.Response, error) {
+				return nil, errors.New("db error")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantSlug:   appErrors.ErrInternalServerError.Slug,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&mockAudioProcess{processFunc: tt.mockFunc})
+
+			var body []byte
+			if s, ok := tt.body.(string); ok {
+				body = []byte(s)
+			} else if tt.body != nil {
+				body, _ = json.Marshal(tt.body)
+			}
+
+			req := httptest.NewRequest(tt.method, "/captcha/audio", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+
+			h.Handle(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Handle() status = %v, wantStatus %v", rr.Code, tt.wantStatus)
+			}
+
+			if tt.wantSlug != "" {
+				var resp map[string]string
+				json.NewDecoder(rr.Body).Decode(&resp)
+				if resp["error"] != tt.wantSlug {
+					t.Errorf("Handle() error slug = %v, wantSlug %v", resp["error"], tt.wantSlug)
+				}
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
 	process "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha"
 )
@@ -13,31 +14,38 @@ type CaptchaProcess interface {
 	Process(ctx context.Context, req process.Request) (*process.Response, error)
 }
 
+type ClientResolver interface {
+	Resolve(r *http.Request) client.Client
+}
+
 type Handler struct {
-	process CaptchaProcess
+	process  CaptchaProcess
+	resolver ClientResolver
 }
 
-func NewHandler(p CaptchaProcess) *Handler {
+func NewHandler(p CaptchaProcess, resolver ClientResolver) *Handler {
 	return &Handler{
-		process: p,
+		process:  p,
+		resolver: resolver,
 	}
 }
 
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		errors.WriteJSON(w, errors.ErrMethodNotAllowed)
+		errors.WriteJSON(r.Context(), w, errors.ErrMethodNotAllowed)
 		return
 	}
 
 	var req process.Request
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		errors.WriteJSON(w, errors.ErrInvalidInput)
+		errors.WriteJSON(r.Context(), w, errors.ErrInvalidInput.Wrap(err))
 		return
 	}
+	req.Client = h.resolver.Resolve(r)
 
 	resp, err := h.process.Process(r.Context(), req)
 	if err != nil {
-		errors.WriteJSON(w, err)
+		errors.WriteJSON(r.Context(), w, err)
 		return
 	}
 
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
 	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
 	process "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha"
 )
@@ -21,6 +22,14 @@ func (m *mockCaptchaProcess) Process(ctx context.Context, req process.Request) (
 	return m.processFunc(ctx, req)
 }
 
+type mockClientResolver struct {
+	resolveFunc func(r *http.Request) client.Client
+}
+
+func (m *mockClientResolver) Resolve(r *http.Request) client.Client {
+	return m.resolveFunc(r)
+}
+
 func TestHandler_Captcha(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -35,6 +44,9 @@ func TestHandler_Captcha(t *testing.T) {
 			method: http.MethodPost,
 			body:   process.Request{Seed: "s", Signature: "sig", Nonce: "n"},
 			mockFunc: func(ctx context.Context, req process.Request) (*process.Response, error) {
+				if req.Client.IP != "203.0.113.7" {
+					return nil, errors.New("client not resolved")
+				}
 				return &process.Response{CaptchaId: "id-123", CaptchaImg: "img-data"}, nil
 			},
 			wantStatus: http.StatusOK,
@@ -77,7 +89,9 @@ func TestHandler_Captcha(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := NewHandler(&mockCaptchaProcess{processFunc: tt.mockFunc})
+			h := NewHandler(&mockCaptchaProcess{processFunc: tt.mockFunc}, &mockClientResolver{resolveFunc: func(r *http.Request) client.Client {
+				return client.Client{IP: "203.0.113.7"}
+			}})
 
 			var body []byte
 			if s, ok := tt.body.(string); ok {
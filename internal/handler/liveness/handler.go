@@ -0,0 +1,28 @@
+package liveness
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type Response struct {
+	Status string `json:"status"`
+}
+
+type Handler struct{}
+
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		errors.WriteJSON(r.Context(), w, errors.ErrMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{Status: "ok"})
+}
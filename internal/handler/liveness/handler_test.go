@@ -0,0 +1,30 @@
+package liveness
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandler_Liveness(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		wantStatus int
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusOK},
+		{name: "head", method: http.MethodHead, wantStatus: http.StatusOK},
+		{name: "wrong method", method: http.MethodPost, wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			NewHandler().Handle(rr, httptest.NewRequest(tt.method, "/healthz", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Handle() status = %v, wantStatus %v", rr.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// maxErrorBody bounds how much of an error response is kept to read its slug.
+const maxErrorBody = 512
+
+type MetricsRecorder interface {
+	RequestStarted(endpoint string)
+	RequestFinished(endpoint string, status int, slug string)
+}
+
+type Metrics struct {
+	recorder MetricsRecorder
+}
+
+func NewMetrics(recorder MetricsRecorder) *Metrics {
+	return &Metrics{
+		recorder: recorder,
+	}
+}
+
+func (m *Metrics) Wrap(endpoint string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.recorder.RequestStarted(endpoint)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			m.recorder.RequestFinished(endpoint, rec.status, rec.slug())
+		}()
+
+		next(rec, r)
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status    int
+	errorBody bytes.Buffer
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status >= http.StatusBadRequest && r.errorBody.Len() < maxErrorBody {
+		r.errorBody.Write(b[:min(len(b), maxErrorBody-r.errorBody.Len())])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// slug reads the slug written by errors.WriteJSON.
+func (r *statusRecorder) slug() string {
+	if r.status < http.StatusBadRequest {
+		return ""
+	}
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(r.errorBody.Bytes(), &body); err != nil {
+		return ""
+	}
+	return body.Error
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type finishedRequest struct {
+	endpoint string
+	status   int
+	slug     string
+}
+
+type mockMetricsRecorder struct {
+	started  []string
+	finished []finishedRequest
+}
+
+func (m *mockMetricsRecorder) RequestStarted(endpoint string) {
+	m.started = append(m.started, endpoint)
+}
+
+func (m *mockMetricsRecorder) RequestFinished(endpoint string, status int, slug string) {
+	m.finished = append(m.finished, finishedRequest{endpoint: endpoint, status: status, slug: slug})
+}
+
+func TestMetrics_Wrap(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    finishedRequest
+	}{
+		{
+			name: "implicit ok",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"captchaId":"id"}`))
+			},
+			want: finishedRequest{endpoint: "captcha", status: http.StatusOK},
+		},
+		{
+			name: "app error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				errors.WriteJSON(r.Context(), w, errors.ErrInsufficientWork)
+			},
+			want: finishedRequest{endpoint: "captcha", status: errors.ErrInsufficientWork.HTTPStatus, slug: errors.ErrInsufficientWork.Slug},
+		},
+		{
+			name: "error without json body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "bad gateway", http.StatusBadGateway)
+			},
+			want: finishedRequest{endpoint: "captcha", status: http.StatusBadGateway},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &mockMetricsRecorder{}
+			h := NewMetrics(recorder).Wrap("captcha", tt.handler)
+
+			rr := httptest.NewRecorder()
+			h(rr, httptest.NewRequest(http.MethodPost, "/captcha", nil))
+
+			if len(recorder.started) != 1 || recorder.started[0] != "captcha" {
+				t.Errorf("started = %v", recorder.started)
+			}
+			if len(recorder.finished) != 1 || recorder.finished[0] != tt.want {
+				t.Errorf("finished = %+v, want %+v", recorder.finished, tt.want)
+			}
+			if rr.Code != tt.want.status {
+				t.Errorf("status = %d, want %d", rr.Code, tt.want.status)
+			}
+		})
+	}
+}
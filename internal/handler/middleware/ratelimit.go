@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+type ClientIPResolver interface {
+	IP(r *http.Request) string
+}
+
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimit struct {
+	limiter   Limiter
+	resolver  ClientIPResolver
+	allowlist client.Networks
+}
+
+func NewRateLimit(limiter Limiter, resolver ClientIPResolver, allowlist client.Networks) *RateLimit {
+	return &RateLimit{
+		limiter:   limiter,
+		resolver:  resolver,
+		allowlist: allowlist,
+	}
+}
+
+func (m *RateLimit) Wrap(endpoint string, rule Rule, next http.HandlerFunc) http.HandlerFunc {
+	if rule.Limit <= 0 || rule.Window <= 0 {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ip := net.ParseIP(m.resolver.IP(r))
+		if ip != nil && m.allowlist.Contains(ip) {
+			next(w, r)
+			return
+		}
+
+		allowed, retryAfter, err := m.limiter.Allow(r.Context(), endpoint+":"+clientKey(ip, r), rule.Limit, rule.Window)
+		if err == nil && !allowed {
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+			if seconds < 1 {
+				seconds = 1
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			errors.WriteJSON(r.Context(), w, errors.ErrRateLimited)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+func clientKey(ip net.IP, r *http.Request) string {
+	if ip == nil {
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			return host
+		}
+		return r.RemoteAddr
+	}
+	if ip.To4() == nil {
+		return ip.Mask(net.CIDRMask(64, 128)).String() + "/64"
+	}
+	return ip.String()
+}
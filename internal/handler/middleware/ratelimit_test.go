@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+)
+
+type mockLimiter struct {
+	allowFunc func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+func (m *mockLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	return m.allowFunc(ctx, key, limit, window)
+}
+
+type mockClientIPResolver struct {
+	ipFunc func(r *http.Request) string
+}
+
+func (m *mockClientIPResolver) IP(r *http.Request) string {
+	return m.ipFunc(r)
+}
+
+func TestRateLimit_Wrap(t *testing.T) {
+	allowlist, err := client.ParseNetworks([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rule := Rule{Limit: 5, Window: time.Minute}
+
+	tests := []struct {
+		name        string
+		ip          string
+		rule        Rule
+		allowFunc   func(context.Context, string, int, time.Duration) (bool, time.Duration, error)
+		wantStatus  int
+		wantRetry   string
+		wantKey     string
+		wantLimited bool
+	}{
+		{
+			name: "allowed",
+			ip:   "203.0.113.7",
+			rule: rule,
+			allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				return true, 0, nil
+			},
+			wantStatus:  http.StatusOK,
+			wantKey:     "pow:203.0.113.7",
+			wantLimited: true,
+		},
+		{
+			name: "limited",
+			ip:   "203.0.113.7",
+			rule: rule,
+			allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				return false, 2500 * time.Millisecond, nil
+			},
+			wantStatus:  http.StatusTooManyRequests,
+			wantRetry:   "3",
+			wantKey:     "pow:203.0.113.7",
+			wantLimited: true,
+		},
+		{
+			name: "retry after is at least a second",
+			ip:   "203.0.113.7",
+			rule: rule,
+			allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				return false, 0, nil
+			},
+			wantStatus:  http.StatusTooManyRequests,
+			wantRetry:   "1",
+			wantKey:     "pow:203.0.113.7",
+			wantLimited: true,
+		},
+		{
+			name: "ipv6 grouped by prefix",
+			ip:   "2001:db8:1:2:aaaa::1",
+			rule: rule,
+			allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				return true, 0, nil
+			},
+			wantStatus:  http.StatusOK,
+			wantKey:     "pow:2001:db8:1:2::/64",
+			wantLimited: true,
+		},
+		{
+			name: "unresolved ip keyed without port",
+			ip:   "",
+			rule: rule,
+			allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				return true, 0, nil
+			},
+			wantStatus:  http.StatusOK,
+			wantKey:     "pow:192.0.2.1",
+			wantLimited: true,
+		},
+		{
+			name: "allowlisted",
+			ip:   "10.1.2.3",
+			rule: rule,
+			allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				return false, time.Second, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "unlimited endpoint",
+			ip:   "203.0.113.7",
+			rule: Rule{},
+			allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				return false, time.Second, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "limiter error fails open",
+			ip:   "203.0.113.7",
+			rule: rule,
+			allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				return false, 0, errors.New("down")
+			},
+			wantStatus:  http.StatusOK,
+			wantKey:     "pow:203.0.113.7",
+			wantLimited: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limited := false
+			limiter := &mockLimiter{allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+				limited = true
+				if key != tt.wantKey || limit != tt.rule.Limit || window != tt.rule.Window {
+					t.Errorf("unexpected limiter call: %s %d %v", key, limit, window)
+				}
+				return tt.allowFunc(ctx, key, limit, window)
+			}}
+			resolver := &mockClientIPResolver{ipFunc: func(r *http.Request) string { return tt.ip }}
+			next := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+			h := NewRateLimit(limiter, resolver, allowlist).Wrap("pow", tt.rule, next)
+			rr := httptest.NewRecorder()
+			h(rr, httptest.NewRequest(http.MethodGet, "/pow", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", rr.Code, tt.wantStatus)
+			}
+			if limited != tt.wantLimited {
+				t.Errorf("limiter called = %v, want %v", limited, tt.wantLimited)
+			}
+			if got := rr.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetry)
+			}
+			if tt.wantStatus == http.StatusTooManyRequests {
+				var resp map[string]string
+				json.NewDecoder(rr.Body).Decode(&resp)
+				if resp["error"] != "error_rate_limited" {
+					t.Errorf("slug = %v, want error_rate_limited", resp["error"])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		inbound   string
+		wantReuse bool
+	}{
+		{name: "inbound id", inbound: "req-42", wantReuse: true},
+		{name: "no inbound id", inbound: ""},
+		{name: "malformed inbound id", inbound: "req 42\nfake=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			h := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = requestid.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/pow", nil)
+			if tt.inbound != "" {
+				req.Header.Set(requestid.Header, tt.inbound)
+			}
+			rr := httptest.NewRecorder()
+			h.ServeHTTP(rr, req)
+
+			if !requestid.Valid(seen) {
+				t.Fatalf("context id %q is not valid", seen)
+			}
+			if got := rr.Header().Get(requestid.Header); got != seen {
+				t.Errorf("response id = %q, context id = %q", got, seen)
+			}
+			if (seen == tt.inbound) != tt.wantReuse {
+				t.Errorf("context id = %q, inbound = %q, want reuse %v", seen, tt.inbound, tt.wantReuse)
+			}
+		})
+	}
+}
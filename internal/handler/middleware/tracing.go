@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/requestid"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+type Trace struct {
+	trusted client.Networks
+}
+
+func NewTrace(trustedProxies client.Networks) *Trace {
+	return &Trace{
+		trusted: trustedProxies,
+	}
+}
+
+// Wrap continues an inbound traceparent only when it comes from a trusted proxy.
+func (m *Trace) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := m.extract(r)
+		ctx, span := tracing.Start(ctx, r.Method+" "+r.URL.Path,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("request.id", requestid.FromContext(ctx)),
+			),
+		)
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", rec.status))
+		if slug := rec.slug(); slug != "" {
+			span.SetAttributes(attribute.String("error.type", slug))
+		}
+		if rec.status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rec.status))
+		}
+	}
+}
+
+func (m *Trace) extract(r *http.Request) context.Context {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip == nil || !m.trusted.Contains(ip) {
+		return r.Context()
+	}
+	return otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+}
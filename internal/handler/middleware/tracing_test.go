@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+func TestTrace(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	trusted, _ := client.ParseNetworks([]string{"10.0.0.0/8"})
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		traceparent  string
+		err          error
+		wantStatus   codes.Code
+		wantSlug     string
+		wantContinue bool
+	}{
+		{name: "success", wantStatus: codes.Unset},
+		{name: "inbound trace from trusted proxy", remoteAddr: "10.0.0.1:4000", traceparent: traceparent, wantStatus: codes.Unset, wantContinue: true},
+		{name: "inbound trace from public client", traceparent: traceparent, wantStatus: codes.Unset},
+		{name: "client error", err: errors.ErrInvalidCaptchaValue, wantStatus: codes.Unset, wantSlug: errors.ErrInvalidCaptchaValue.Slug},
+		{name: "server error", err: errors.ErrInternalServerError, wantStatus: codes.Error, wantSlug: errors.ErrInternalServerError.Slug},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter.Reset()
+			var inner trace.SpanContext
+			h := NewTrace(trusted).Wrap(func(w http.ResponseWriter, r *http.Request) {
+				inner = trace.SpanContextFromContext(r.Context())
+				if tt.err != nil {
+					errors.WriteJSON(r.Context(), w, tt.err)
+				}
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/verify", nil)
+			if tt.remoteAddr != "" {
+				req.RemoteAddr = tt.remoteAddr
+			}
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			h(httptest.NewRecorder(), req)
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name != "POST /verify" || span.SpanKind != trace.SpanKindServer {
+				t.Errorf("unexpected span %q of kind %v", span.Name, span.SpanKind)
+			}
+			if inner.SpanID() != span.SpanContext.SpanID() {
+				t.Error("handler context does not carry the server span")
+			}
+			if continued := span.SpanContext.TraceID().String() == "4bf92f3577b34da6a3ce929d0e0e4736"; continued != tt.wantContinue {
+				t.Errorf("trace id = %s, continued = %v, want %v", span.SpanContext.TraceID(), continued, tt.wantContinue)
+			}
+			if span.Status.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", span.Status.Code, tt.wantStatus)
+			}
+
+			var slug string
+			for _, a := range span.Attributes {
+				if a.Key == "error.type" {
+					slug = a.Value.AsString()
+				}
+			}
+			if slug != tt.wantSlug {
+				t.Errorf("error.type = %q, want %q", slug, tt.wantSlug)
+			}
+		})
+	}
+}
@@ -5,33 +5,40 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
 	process "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/pow"
 )
 
 type PowProcess interface {
-	Process(ctx context.Context) (*process.Response, error)
+	Process(ctx context.Context, req process.Request) (*process.Response, error)
+}
+
+type ClientResolver interface {
+	Resolve(r *http.Request) client.Client
 }
 
 type Handler struct {
-	process PowProcess
+	process  PowProcess
+	resolver ClientResolver
 }
 
-func NewHandler(p PowProcess) *Handler {
+func NewHandler(p PowProcess, resolver ClientResolver) *Handler {
 	return &Handler{
-		process: p,
+		process:  p,
+		resolver: resolver,
 	}
 }
 
 func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		errors.WriteJSON(w, errors.ErrMethodNotAllowed)
+		errors.WriteJSON(r.Context(), w, errors.ErrMethodNotAllowed)
 		return
 	}
 
-	resp, err := h.process.Process(r.Context())
+	resp, err := h.process.Process(r.Context(), process.Request{Client: h.resolver.Resolve(r)})
 	if err != nil {
-		errors.WriteJSON(w, err)
+		errors.WriteJSON(r.Context(), w, err)
 		return
 	}
 
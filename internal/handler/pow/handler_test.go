@@ -8,29 +8,41 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
 	processPow "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/pow"
 )
 
 type mockPowProcess struct {
-	processFunc func(ctx context.Context) (*processPow.Response, error)
+	processFunc func(ctx context.Context, req processPow.Request) (*processPow.Response, error)
 }
 
-func (m *mockPowProcess) Process(ctx context.Context) (*processPow.Response, error) {
-	return m.processFunc(ctx)
+func (m *mockPowProcess) Process(ctx context.Context, req processPow.Request) (*processPow.Response, error) {
+	return m.processFunc(ctx, req)
+}
+
+type mockClientResolver struct {
+	resolveFunc func(r *http.Request) client.Client
+}
+
+func (m *mockClientResolver) Resolve(r *http.Request) client.Client {
+	return m.resolveFunc(r)
 }
 
 func TestHandler_Pow(t *testing.T) {
 	tests := []struct {
 		name       string
 		method     string
-		mockFunc   func(context.Context) (*processPow.Response, error)
+		mockFunc   func(context.Context, processPow.Request) (*processPow.Response, error)
 		wantStatus int
 		wantSlug   string
 	}{
 		{
 			name:   "success",
 			method: http.MethodGet,
-			mockFunc: func(ctx context.Context) (*processPow.Response, error) {
+			mockFunc: func(ctx context.Context, req processPow.Request) (*processPow.Response, error) {
+				if req.Client.IP != "203.0.113.7" {
+					return nil, errors.New("client not resolved")
+				}
 				return &processPow.Response{Seed: "s", Signature: "sig"}, nil
 			},
 			wantStatus: http.StatusOK,
@@ -38,7 +50,7 @@ func TestHandler_Pow(t *testing.T) {
 		{
 			name:   "method not allowed",
 			method: http.MethodPost,
-			mockFunc: func(ctx context.Context) (*processPow.Response, error) {
+			mockFunc: func(ctx context.Context, req processPow.Request) (*processPow.Response, error) {
 				return nil, nil
 			},
 			wantStatus: http.StatusMethodNotAllowed,
@@ -47,7 +59,7 @@ func TestHandler_Pow(t *testing.T) {
 		{
 			name:   "process error",
 			method: http.MethodGet,
-			mockFunc: func(ctx context.Context) (*processPow.Response, error) {
+			mockFunc: func(ctx context.Context, req processPow.Request) (*processPow.Response, error) {
 				return nil, errors.New("internal fail")
 			},
 			wantStatus: http.StatusInternalServerError,
@@ -57,7 +69,9 @@ func TestHandler_Pow(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := NewHandler(&mockPowProcess{processFunc: tt.mockFunc})
+			h := NewHandler(&mockPowProcess{processFunc: tt.mockFunc}, &mockClientResolver{resolveFunc: func(r *http.Request) client.Client {
+				return client.Client{IP: "203.0.113.7"}
+			}})
 			req := httptest.NewRequest(tt.method, "/pow", nil)
 			rr := httptest.NewRecorder()
 
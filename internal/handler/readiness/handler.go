@@ -0,0 +1,40 @@
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	processReadiness "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/readiness"
+)
+
+type ReadinessProcess interface {
+	Process(ctx context.Context) (*processReadiness.Response, error)
+}
+
+type Handler struct {
+	process ReadinessProcess
+}
+
+func NewHandler(p ReadinessProcess) *Handler {
+	return &Handler{
+		process: p,
+	}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		errors.WriteJSON(r.Context(), w, errors.ErrMethodNotAllowed)
+		return
+	}
+
+	resp, err := h.process.Process(r.Context())
+	if err != nil {
+		errors.WriteJSON(r.Context(), w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,87 @@
+package readiness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	processReadiness "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/readiness"
+)
+
+type mockReadinessProcess struct {
+	processFunc func(ctx context.Context) (*processReadiness.Response, error)
+}
+
+func (m *mockReadinessProcess) Process(ctx context.Context) (*processReadiness.Response, error) {
+	return m.processFunc(ctx)
+}
+
+func TestHandler_Readiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		mockFunc   func(context.Context) (*processReadiness.Response, error)
+		wantStatus int
+		wantSlug   string
+	}{
+		{
+			name:   "ready",
+			method: http.MethodGet,
+			mockFunc: func(ctx context.Context) (*processReadiness.Response, error) {
+				return &processReadiness.Response{Status: "ready"}, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantSlug:   appErrors.ErrMethodNotAllowed.Slug,
+		},
+		{
+			name:   "shutting down",
+			method: http.MethodGet,
+			mockFunc: func(ctx context.Context) (*processReadiness.Response, error) {
+				return nil, appErrors.ErrShuttingDown
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantSlug:   appErrors.ErrShuttingDown.Slug,
+		},
+		{
+			name:   "redis unavailable",
+			method: http.MethodGet,
+			mockFunc: func(ctx context.Context) (*processReadiness.Response, error) {
+				return nil, appErrors.ErrRedisUnavailable.Wrap(errors.New("connection refused"))
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantSlug:   appErrors.ErrRedisUnavailable.Slug,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&mockReadinessProcess{processFunc: tt.mockFunc})
+
+			req := httptest.NewRequest(tt.method, "/readyz", nil)
+			rr := httptest.NewRecorder()
+
+			h.Handle(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Handle() status = %v, wantStatus %v", rr.Code, tt.wantStatus)
+			}
+
+			if tt.wantSlug != "" {
+				var resp map[string]string
+				json.NewDecoder(rr.Body).Decode(&resp)
+				if resp["error"] != tt.wantSlug {
+					t.Errorf("Handle() error slug = %v, wantSlug %v", resp["error"], tt.wantSlug)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package refresh
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	processRefresh "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/refresh"
+)
+
+type RefreshProcess interface {
+	Process(ctx context.Context, req processRefresh.Request) (*processRefresh.Response, error)
+}
+
+type ClientResolver interface {
+	Resolve(r *http.Request) client.Client
+}
+
+type Handler struct {
+	process  RefreshProcess
+	resolver ClientResolver
+}
+
+func NewHandler(p RefreshProcess, resolver ClientResolver) *Handler {
+	return &Handler{
+		process:  p,
+		resolver: resolver,
+	}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errors.WriteJSON(r.Context(), w, errors.ErrMethodNotAllowed)
+		return
+	}
+
+	var req processRefresh.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errors.WriteJSON(r.Context(), w, errors.ErrInvalidInput.Wrap(err))
+		return
+	}
+	req.Client = h.resolver.Resolve(r)
+
+	resp, err := h.process.Process(r.Context(), req)
+	if err != nil {
+		errors.WriteJSON(r.Context(), w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,121 @@
+package refresh
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	processRefresh "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/refresh"
+)
+
+type mockRefreshProcess struct {
+	processFunc func(ctx context.Context, req processRefresh.Request) (*processRefresh.Response, error)
+}
+
+func (m *mockRefreshProcess) Process(ctx context.Context, req processRefresh.Request) (*processRefresh.Response, error) {
+	return m.processFunc(ctx, req)
+}
+
+type mockClientResolver struct {
+	resolveFunc func(r *http.Request) client.Client
+}
+
+func (m *mockClientResolver) Resolve(r *http.Request) client.Client {
+	return m.resolveFunc(r)
+}
+
+func TestHandler_Refresh(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       interface{}
+		mockFunc   func(context.Context, processRefresh.Request) (*processRefresh.Response, error)
+		wantStatus int
+		wantSlug   string
+	}{
+		{
+			name:   "valid request",
+			method: http.MethodPost,
+			body:   processRefresh.Request{CaptchaId: "id-123"},
+			mockFunc: func(ctx context.Context, req processRefresh.Request) (*processRefresh.Response, error) {
+				if req.Client.IP != "203.0.113.7" {
+					return nil, errors.New("client not resolved")
+				}
+				return &processRefresh.Response{CaptchaId: "id-123", CaptchaImg: "img"}, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			body:       nil,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantSlug:   appErrors.ErrMethodNotAllowed.Slug,
+		},
+		{
+			name:       "invalid json",
+			method:     http.MethodPost,
+			body:       "{invalid-json}",
+			wantStatus: http.StatusBadRequest,
+			wantSlug:   appErrors.ErrInvalidInput.Slug,
+		},
+		{
+			name:   "refresh limit reached",
+			method: http.MethodPost,
+			body:   processRefresh.Request{CaptchaId: "id-123"},
+			mockFunc: func(ctx context.Context, req processRefresh.Request) (*processRefresh.Response, error) {
+				return nil, appErrors.ErrRefreshLimit
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantSlug:   appErrors.ErrRefreshLimit.Slug,
+		},
+		{
+			name:   "process returns unknown error",
+			method: http.MethodPost,
+			body:   processRefresh.Request{CaptchaId: "id-123"},
+			mockFunc: func(ctx context.Context, req processRefresh.Request) (*processRefresh.Response, error) {
+				return nil, errors.New("db error")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantSlug:   appErrors.ErrInternalServerError.Slug,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&mockRefreshProcess{processFunc: tt.mockFunc}, &mockClientResolver{resolveFunc: func(r *http.Request) client.Client {
+				return client.Client{IP: "203.0.113.7"}
+			}})
+
+			var body []byte
+			if s, ok := tt.body.(string); ok {
+				body = []byte(s)
+			} else if tt.body != nil {
+				body, _ = json.Marshal(tt.body)
+			}
+
+			req := httptest.NewRequest(tt.method, "/captcha/refresh", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+
+			h.Handle(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Handle() status = %v, wantStatus %v", rr.Code, tt.wantStatus)
+			}
+
+			if tt.wantSlug != "" {
+				var resp map[string]string
+				json.NewDecoder(rr.Body).Decode(&resp)
+				if resp["error"] != tt.wantSlug {
+					t.Errorf("Handle() error slug = %v, wantSlug %v", resp["error"], tt.wantSlug)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package siteverify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	processSiteverify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/siteverify"
+)
+
+type SiteverifyProcess interface {
+	Process(ctx context.Context, req processSiteverify.Request) (*processSiteverify.Response, error)
+}
+
+type Handler struct {
+	process SiteverifyProcess
+}
+
+func NewHandler(p SiteverifyProcess) *Handler {
+	return &Handler{
+		process: p,
+	}
+}
+
+func (h *Handler) Handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		errors.WriteJSON(r.Context(), w, errors.ErrMethodNotAllowed)
+		return
+	}
+
+	var req processSiteverify.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		errors.WriteJSON(r.Context(), w, errors.ErrInvalidInput.Wrap(err))
+		return
+	}
+
+	resp, err := h.process.Process(r.Context(), req)
+	if err != nil {
+		errors.WriteJSON(r.Context(), w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,117 @@
+package siteverify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	processSiteverify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/siteverify"
+)
+
+type mockSiteverifyProcess struct {
+	processFunc func(ctx context.Context, req processSiteverify.Request) (*processSiteverify.Response, error)
+}
+
+func (m *mockSiteverifyProcess) Process(ctx context.Context, req processSiteverify.Request) (*processSiteverify.Response, error) {
+	return m.processFunc(ctx, req)
+}
+
+func TestHandler_Siteverify(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       interface{}
+		mockFunc   func(context.Context, processSiteverify.Request) (*processSiteverify.Response, error)
+		wantStatus int
+		wantSlug   string
+	}{
+		{
+			name:   "valid request",
+			method: http.MethodPost,
+			body:   processSiteverify.Request{CaptchaId: "id-123", Secret: "secret"},
+			mockFunc: func(ctx context.Context, req processSiteverify.Request) (*processSiteverify.Response, error) {
+				return &processSiteverify.Response{CaptchaId: "id-123", Success: true}, nil
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			body:       nil,
+			wantStatus: http.StatusMethodNotAllowed,
+			wantSlug:   appErrors.ErrMethodNotAllowed.Slug,
+		},
+		{
+			name:       "invalid json",
+			method:     http.MethodPost,
+			body:       "{invalid-json}",
+			wantStatus: http.StatusBadRequest,
+			wantSlug:   appErrors.ErrInvalidInput.Slug,
+		},
+		{
+			name:   "unauthorized service",
+			method: http.MethodPost,
+			body:   processSiteverify.Request{CaptchaId: "id-123", Secret: "wrong"},
+			mockFunc: func(ctx context.Context, req processSiteverify.Request) (*processSiteverify.Response, error) {
+				return nil, appErrors.ErrUnauthorizedService
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantSlug:   appErrors.ErrUnauthorizedService.Slug,
+		},
+		{
+			name:   "captcha not solved",
+			method: http.MethodPost,
+			body:   processSiteverify.Request{CaptchaId: "id-123", Secret: "secret"},
+			mockFunc: func(ctx context.Context, req processSiteverify.Request) (*processSiteverify.Response, error) {
+				return nil, appErrors.ErrCaptchaNotSolved
+			},
+			wantStatus: http.StatusConflict,
+			wantSlug:   appErrors.ErrCaptchaNotSolved.Slug,
+		},
+		{
+			name:   "process returns unknown error",
+			method: http.MethodPost,
+			body:   processSiteverify.Request{CaptchaId: "id-123", Secret: "secret"},
+			mockFunc: func(ctx context.Context, req processSiteverify.Request) (*processSiteverify.Response, error) {
+				return nil, errors.New("db error")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantSlug:   appErrors.ErrInternalServerError.Slug,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&mockSiteverifyProcess{processFunc: tt.mockFunc})
+
+			var body []byte
+			if s, ok := tt.body.(string); ok {
+				body = []byte(s)
+			} else if tt.body != nil {
+				body, _ = json.Marshal(tt.body)
+			}
+
+			req := httptest.NewRequest(tt.method, "/siteverify", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+
+			h.Handle(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("Handle() status = %v, wantStatus %v", rr.Code, tt.wantStatus)
+			}
+
+			if tt.wantSlug != "" {
+				var resp map[string]string
+				json.NewDecoder(rr.Body).Decode(&resp)
+				if resp["error"] != tt.wantSlug {
+					t.Errorf("Handle() error slug = %v, wantSlug %v", resp["error"], tt.wantSlug)
+				}
+			}
+		})
+	}
+}
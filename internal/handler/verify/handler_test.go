@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
 	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
 	processVerify "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/verify"
 )
@@ -21,6 +22,14 @@ func (m *mockVerifyProcess) Process(ctx context.Context, req processVerify.Reque
 	return m.processFunc(ctx, req)
 }
 
+type mockClientResolver struct {
+	resolveFunc func(r *http.Request) client.Client
+}
+
+func (m *mockClientResolver) Resolve(r *http.Request) client.Client {
+	return m.resolveFunc(r)
+}
+
 func TestHandler_Verify(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -35,6 +44,9 @@ func TestHandler_Verify(t *testing.T) {
 			method: http.MethodPost,
 			body:   processVerify.Request{CaptchaId: "id-123", CaptchaValue: "val-123"},
 			mockFunc: func(ctx context.Context, req processVerify.Request) (*processVerify.Response, error) {
+				if req.Client.IP != "203.0.113.7" {
+					return nil, errors.New("client not resolved")
+				}
 				return &processVerify.Response{CaptchaId: "id-123"}, nil
 			},
 			wantStatus: http.StatusOK,
@@ -77,7 +89,9 @@ func TestHandler_Verify(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			h := NewHandler(&mockVerifyProcess{processFunc: tt.mockFunc})
+			h := NewHandler(&mockVerifyProcess{processFunc: tt.mockFunc}, &mockClientResolver{resolveFunc: func(r *http.Request) client.Client {
+				return client.Client{IP: "203.0.113.7"}
+			}})
 
 			var body []byte
 			if s, ok := tt.body.(string); ok {
@@ -0,0 +1,9 @@
+package audio
+
+var Languages = map[string]bool{
+	"en": true,
+	"de": true,
+	"ja": true,
+	"ru": true,
+	"zh": true,
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const SiteKeyHeader = "X-Site-Key"
+
+const (
+	FieldIP        = "ip"
+	FieldUserAgent = "userAgent"
+	FieldSite      = "site"
+)
+
+// Client describes who is talking to the service, as far as a request tells.
+type Client struct {
+	IP        string
+	UserAgent string
+	SiteKey   string
+}
+
+type Resolver struct {
+	trusted Networks
+}
+
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	trusted, err := ParseNetworks(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return &Resolver{trusted: trusted}, nil
+}
+
+func (r *Resolver) Resolve(req *http.Request) Client {
+	return Client{
+		IP:        r.IP(req),
+		UserAgent: req.UserAgent(),
+		SiteKey:   req.Header.Get(SiteKeyHeader),
+	}
+}
+
+func (r *Resolver) IP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return host
+	}
+	if !r.trusted.Contains(ip) {
+		return ip.String()
+	}
+
+	hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+		if !r.trusted.Contains(hop) {
+			break
+		}
+	}
+
+	return ip.String()
+}
+
+// Networks is a list of addresses and CIDR ranges.
+type Networks []*net.IPNet
+
+func ParseNetworks(cidrs []string) (Networks, error) {
+	var networks Networks
+	for _, cidr := range cidrs {
+		network, err := parseNetwork(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func (n Networks) Contains(ip net.IP) bool {
+	for _, network := range n {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseNetwork(s string) (*net.IPNet, error) {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("client: invalid address %q", s)
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, fmt.Errorf("client: invalid network %q: %w", s, err)
+	}
+	return network, nil
+}
+
+// Binding selects which parts of the client a seed is bound to.
+type Binding struct {
+	fields []string
+}
+
+func NewBinding(fields []string) (Binding, error) {
+	for _, f := range fields {
+		switch f {
+		case FieldIP, FieldUserAgent, FieldSite:
+		default:
+			return Binding{}, fmt.Errorf("client: unknown binding field %q", f)
+		}
+	}
+	return Binding{fields: fields}, nil
+}
+
+func (b Binding) Enabled() bool {
+	return len(b.fields) > 0
+}
+
+// Hash returns a digest of the bound fields, or "" when binding is disabled.
+func (b Binding) Hash(c Client) string {
+	if !b.Enabled() {
+		return ""
+	}
+
+	h := sha256.New()
+	for _, f := range b.fields {
+		switch f {
+		case FieldIP:
+			h.Write([]byte(c.IP))
+		case FieldUserAgent:
+			h.Write([]byte(c.UserAgent))
+		case FieldSite:
+			h.Write([]byte(c.SiteKey))
+		}
+		h.Write([]byte{0})
+	}
+
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil)[:16])
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolver_IP(t *testing.T) {
+	r, err := NewResolver([]string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		want       string
+	}{
+		{name: "direct client", remoteAddr: "203.0.113.7:5000", want: "203.0.113.7"},
+		{name: "untrusted peer cannot spoof", remoteAddr: "203.0.113.7:5000", xff: []string{"1.2.3.4"}, want: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.1.2.3:5000", xff: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed hop left of real client", remoteAddr: "10.1.2.3:5000", xff: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "proxy chain", remoteAddr: "10.1.2.3:5000", xff: []string{"198.51.100.1, 192.168.1.1", "10.9.9.9"}, want: "198.51.100.1"},
+		{name: "only proxies", remoteAddr: "10.1.2.3:5000", xff: []string{"10.2.2.2"}, want: "10.2.2.2"},
+		{name: "trusted proxy without header", remoteAddr: "10.1.2.3:5000", want: "10.1.2.3"},
+		{name: "garbage hop stops the walk", remoteAddr: "10.1.2.3:5000", xff: []string{"198.51.100.1, junk"}, want: "10.1.2.3"},
+		{name: "ipv6", remoteAddr: "[fd00::1]:5000", xff: []string{"2001:db8::1"}, want: "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/pow", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if got := r.IP(req); got != tt.want {
+				t.Errorf("IP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolver_Invalid(t *testing.T) {
+	for _, proxy := range []string{"10.0.0.0/33", "not-an-ip"} {
+		if _, err := NewResolver([]string{proxy}); err == nil {
+			t.Errorf("expected error for %q", proxy)
+		}
+	}
+}
+
+func TestNetworks_Contains(t *testing.T) {
+	n, err := ParseNetworks([]string{"10.0.0.0/8", "203.0.113.7", "2001:db8::/32"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for ip, want := range map[string]bool{"10.20.30.40": true, "203.0.113.7": true, "203.0.113.8": false, "2001:db8::1": true, "2001:db9::1": false} {
+		if got := n.Contains(net.ParseIP(ip)); got != want {
+			t.Errorf("Contains(%s) = %v, want %v", ip, got, want)
+		}
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	r, _ := NewResolver(nil)
+	req := httptest.NewRequest("POST", "/captcha", nil)
+	req.RemoteAddr = "203.0.113.7:5000"
+	req.Header.Set("User-Agent", "test-agent")
+	req.Header.Set(SiteKeyHeader, "site-1")
+
+	got := r.Resolve(req)
+	if got != (Client{IP: "203.0.113.7", UserAgent: "test-agent", SiteKey: "site-1"}) {
+		t.Errorf("unexpected client: %+v", got)
+	}
+}
+
+func TestBinding(t *testing.T) {
+	if _, err := NewBinding([]string{"ip", "cookie"}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+
+	disabled, _ := NewBinding(nil)
+	if disabled.Enabled() || disabled.Hash(Client{IP: "1.2.3.4"}) != "" {
+		t.Error("disabled binding should produce no hash")
+	}
+
+	b, _ := NewBinding([]string{FieldIP, FieldUserAgent})
+	c := Client{IP: "1.2.3.4", UserAgent: "ua", SiteKey: "a"}
+
+	if b.Hash(c) != b.Hash(Client{IP: "1.2.3.4", UserAgent: "ua", SiteKey: "b"}) {
+		t.Error("unbound field should not change the hash")
+	}
+	if b.Hash(c) == b.Hash(Client{IP: "1.2.3.5", UserAgent: "ua"}) {
+		t.Error("ip change should change the hash")
+	}
+	if b.Hash(Client{IP: "1.2.3.4", UserAgent: "ua"}) == b.Hash(Client{IP: "1.2.3.4u", UserAgent: "a"}) {
+		t.Error("field boundaries should be part of the hash")
+	}
+}
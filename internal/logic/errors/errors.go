@@ -1,8 +1,10 @@
 package errors
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
 	"net/http"
 )
 
@@ -23,24 +25,55 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+func (e *AppError) Wrap(err error) *AppError {
+	return &AppError{HTTPStatus: e.HTTPStatus, Slug: e.Slug, Err: err}
+}
+
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.HTTPStatus == e.HTTPStatus && t.Slug == e.Slug
+}
+
 var (
 	ErrInternalServerError = &AppError{HTTPStatus: http.StatusInternalServerError, Slug: "error_captcha_server"}
 	ErrInvalidSignature    = &AppError{HTTPStatus: http.StatusForbidden, Slug: "error_pow_signature"}
 	ErrSeedAlreadyUsed     = &AppError{HTTPStatus: http.StatusConflict, Slug: "error_pow_double_spend"}
 	ErrPowExpired          = &AppError{HTTPStatus: http.StatusGone, Slug: "error_pow_expired"}
 	ErrInsufficientWork    = &AppError{HTTPStatus: http.StatusBadRequest, Slug: "error_pow_work"}
+	ErrSeedClientMismatch  = &AppError{HTTPStatus: http.StatusForbidden, Slug: "error_pow_client_mismatch"}
+	ErrSeedAudience        = &AppError{HTTPStatus: http.StatusForbidden, Slug: "error_pow_audience"}
 	ErrCaptchaNotFound     = &AppError{HTTPStatus: http.StatusNotFound, Slug: "error_captcha_not_found"}
 	ErrInvalidCaptchaValue = &AppError{HTTPStatus: http.StatusBadRequest, Slug: "error_captcha_invalid"}
 	ErrNoTriesLeft         = &AppError{HTTPStatus: http.StatusGone, Slug: "error_captcha_expired"}
+	ErrCaptchaSolved       = &AppError{HTTPStatus: http.StatusConflict, Slug: "error_captcha_already_solved"}
+	ErrRefreshLimit        = &AppError{HTTPStatus: http.StatusTooManyRequests, Slug: "error_captcha_refresh_limit"}
+	ErrCaptchaNotSolved    = &AppError{HTTPStatus: http.StatusConflict, Slug: "error_captcha_not_solved"}
+	ErrCaptchaSite         = &AppError{HTTPStatus: http.StatusForbidden, Slug: "error_captcha_site"}
+	ErrCaptchaMode         = &AppError{HTTPStatus: http.StatusForbidden, Slug: "error_captcha_mode"}
+	ErrUnauthorizedService = &AppError{HTTPStatus: http.StatusUnauthorized, Slug: "error_siteverify_unauthorized"}
+	ErrRateLimited         = &AppError{HTTPStatus: http.StatusTooManyRequests, Slug: "error_rate_limited"}
 	ErrInvalidInput        = &AppError{HTTPStatus: http.StatusBadRequest, Slug: "error_message"}
 	ErrMethodNotAllowed    = &AppError{HTTPStatus: http.StatusMethodNotAllowed, Slug: "error_message"}
+	ErrShuttingDown        = &AppError{HTTPStatus: http.StatusServiceUnavailable, Slug: "error_shutting_down"}
+	ErrInvalidConfig       = &AppError{HTTPStatus: http.StatusServiceUnavailable, Slug: "error_config_invalid"}
+	ErrRedisUnavailable    = &AppError{HTTPStatus: http.StatusServiceUnavailable, Slug: "error_redis_unavailable"}
 )
 
-func WriteJSON(w http.ResponseWriter, err error) {
+func WriteJSON(ctx context.Context, w http.ResponseWriter, err error) {
 	var appErr *AppError
 	if !errors.As(err, &appErr) {
-		appErr = ErrInternalServerError
+		appErr = ErrInternalServerError.Wrap(err)
+	}
+
+	level := slog.LevelInfo
+	if appErr.HTTPStatus >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	attrs := []any{"status", appErr.HTTPStatus, "slug", appErr.Slug}
+	if appErr.Err != nil {
+		attrs = append(attrs, "error", appErr.Err.Error())
 	}
+	slog.Log(ctx, level, "request failed", attrs...)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(appErr.HTTPStatus)
@@ -0,0 +1,99 @@
+package errors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppError_Wrap(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := ErrInternalServerError.Wrap(cause)
+
+	if !errors.Is(err, ErrInternalServerError) {
+		t.Error("wrapped error does not match its AppError")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("wrapped error does not match its cause")
+	}
+	if errors.Is(err, ErrInvalidInput) {
+		t.Error("wrapped error matches another AppError")
+	}
+	if errors.Is(ErrInvalidInput.Wrap(cause), ErrMethodNotAllowed) {
+		t.Error("AppErrors sharing a slug must not match")
+	}
+	if ErrInternalServerError.Err != nil {
+		t.Error("Wrap modified the sentinel")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantSlug   string
+		wantLevel  string
+		wantCause  string
+	}{
+		{
+			name:       "app error",
+			err:        ErrInsufficientWork,
+			wantStatus: http.StatusBadRequest,
+			wantSlug:   ErrInsufficientWork.Slug,
+			wantLevel:  "INFO",
+		},
+		{
+			name:       "wrapped app error",
+			err:        ErrInternalServerError.Wrap(errors.New("redis down")),
+			wantStatus: http.StatusInternalServerError,
+			wantSlug:   ErrInternalServerError.Slug,
+			wantLevel:  "ERROR",
+			wantCause:  "redis down",
+		},
+		{
+			name:       "unknown error",
+			err:        errors.New("boom"),
+			wantStatus: http.StatusInternalServerError,
+			wantSlug:   ErrInternalServerError.Slug,
+			wantLevel:  "ERROR",
+			wantCause:  "boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			defer slog.SetDefault(slog.Default())
+			slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+
+			rr := httptest.NewRecorder()
+			WriteJSON(context.Background(), rr, tt.err)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			var body map[string]string
+			json.NewDecoder(rr.Body).Decode(&body)
+			if body["error"] != tt.wantSlug {
+				t.Errorf("slug = %q, want %q", body["error"], tt.wantSlug)
+			}
+
+			var record map[string]any
+			if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+				t.Fatalf("log record is not JSON: %v", err)
+			}
+			if record["level"] != tt.wantLevel || record["slug"] != tt.wantSlug {
+				t.Errorf("unexpected log record: %v", record)
+			}
+			if cause, _ := record["error"].(string); cause != tt.wantCause {
+				t.Errorf("logged cause = %q, want %q", cause, tt.wantCause)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const LegacyKeyId = "default"
+
+var ErrInvalidKeyring = errors.New("keyring: invalid keyring")
+
+type Key struct {
+	Id        string
+	Secret    string
+	RetiredAt time.Time
+}
+
+type Keyring struct {
+	active string
+	keys   map[string]Key
+	order  []string
+	grace  time.Duration
+	now    func() time.Time
+}
+
+func New(keys []Key, activeId string, grace time.Duration) (*Keyring, error) {
+	k := &Keyring{
+		active: activeId,
+		keys:   make(map[string]Key, len(keys)),
+		grace:  grace,
+		now:    time.Now,
+	}
+
+	for _, key := range keys {
+		if key.Id == "" || key.Secret == "" {
+			return nil, fmt.Errorf("%w: key id and secret are required", ErrInvalidKeyring)
+		}
+		if strings.Contains(key.Id, ".") {
+			return nil, fmt.Errorf("%w: key id %q must not contain '.'", ErrInvalidKeyring, key.Id)
+		}
+		if _, exists := k.keys[key.Id]; exists {
+			return nil, fmt.Errorf("%w: duplicate key id %q", ErrInvalidKeyring, key.Id)
+		}
+		k.keys[key.Id] = key
+		k.order = append(k.order, key.Id)
+	}
+
+	active, ok := k.keys[activeId]
+	if !ok {
+		return nil, fmt.Errorf("%w: active key %q not found", ErrInvalidKeyring, activeId)
+	}
+	if !active.RetiredAt.IsZero() {
+		return nil, fmt.Errorf("%w: active key %q is retired", ErrInvalidKeyring, activeId)
+	}
+
+	return k, nil
+}
+
+func (k *Keyring) Active() Key {
+	return k.keys[k.active]
+}
+
+// Lookup returns the key with the given id if it may still verify signatures.
+func (k *Keyring) Lookup(id string) (Key, bool) {
+	key, ok := k.keys[id]
+	if !ok || !k.usable(key) {
+		return Key{}, false
+	}
+	return key, true
+}
+
+// Verifiers returns every key that may still verify signatures, active first.
+func (k *Keyring) Verifiers() []Key {
+	keys := []Key{k.Active()}
+	for _, id := range k.order {
+		if key := k.keys[id]; id != k.active && k.usable(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (k *Keyring) usable(key Key) bool {
+	return key.RetiredAt.IsZero() || k.now().Before(key.RetiredAt.Add(k.grace))
+}
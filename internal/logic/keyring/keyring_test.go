@@ -0,0 +1,67 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	retired := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		keys     []Key
+		activeId string
+		wantErr  bool
+	}{
+		{name: "single key", keys: []Key{{Id: "k1", Secret: "s1"}}, activeId: "k1"},
+		{name: "rotation", keys: []Key{{Id: "k1", Secret: "s1", RetiredAt: retired}, {Id: "k2", Secret: "s2"}}, activeId: "k2"},
+		{name: "missing active", keys: []Key{{Id: "k1", Secret: "s1"}}, activeId: "k2", wantErr: true},
+		{name: "retired active", keys: []Key{{Id: "k1", Secret: "s1", RetiredAt: retired}}, activeId: "k1", wantErr: true},
+		{name: "duplicate id", keys: []Key{{Id: "k1", Secret: "s1"}, {Id: "k1", Secret: "s2"}}, activeId: "k1", wantErr: true},
+		{name: "empty secret", keys: []Key{{Id: "k1"}}, activeId: "k1", wantErr: true},
+		{name: "dot in id", keys: []Key{{Id: "k.1", Secret: "s1"}}, activeId: "k.1", wantErr: true},
+		{name: "no keys", activeId: "k1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.keys, tt.activeId, time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidKeyring) {
+				t.Errorf("expected ErrInvalidKeyring, got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyring_Retirement(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	k, err := New([]Key{
+		{Id: "old", Secret: "s0", RetiredAt: now.Add(-10 * time.Minute)},
+		{Id: "prev", Secret: "s1", RetiredAt: now.Add(-2 * time.Minute)},
+		{Id: "cur", Secret: "s2"},
+	}, "cur", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	k.now = func() time.Time { return now }
+
+	if k.Active().Id != "cur" {
+		t.Errorf("Active() = %s, want cur", k.Active().Id)
+	}
+
+	for id, want := range map[string]bool{"cur": true, "prev": true, "old": false, "unknown": false} {
+		if _, ok := k.Lookup(id); ok != want {
+			t.Errorf("Lookup(%q) = %v, want %v", id, ok, want)
+		}
+	}
+
+	verifiers := k.Verifiers()
+	if len(verifiers) != 2 || verifiers[0].Id != "cur" || verifiers[1].Id != "prev" {
+		t.Errorf("Verifiers() = %+v", verifiers)
+	}
+}
@@ -0,0 +1,20 @@
+package lifecycle
+
+import "sync/atomic"
+
+type State struct {
+	draining atomic.Bool
+}
+
+func NewState() *State {
+	return &State{}
+}
+
+// Drain marks the service as shutting down. It cannot be undone.
+func (s *State) Drain() {
+	s.draining.Store(true)
+}
+
+func (s *State) Draining() bool {
+	return s.draining.Load()
+}
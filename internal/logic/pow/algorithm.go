@@ -0,0 +1,144 @@
+package pow
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	AlgorithmSHA256   = "sha256"
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmScrypt   = "scrypt"
+)
+
+const digestLength = 32
+
+var ErrInvalidAlgorithm = errors.New("pow: invalid algorithm")
+
+type Algorithm interface {
+	ID() string
+	Digest(seed, nonce string) []byte
+}
+
+type SHA256 struct{}
+
+func (SHA256) ID() string {
+	return AlgorithmSHA256
+}
+
+func (SHA256) Digest(seed, nonce string) []byte {
+	digest := sha256.Sum256([]byte(seed + nonce))
+	return digest[:]
+}
+
+type Argon2id struct {
+	Time      uint32
+	MemoryKiB uint32
+	Threads   uint8
+}
+
+func (a Argon2id) ID() string {
+	return fmt.Sprintf("%s.t%d.m%d.p%d", AlgorithmArgon2id, a.Time, a.MemoryKiB, a.Threads)
+}
+
+func (a Argon2id) Digest(seed, nonce string) []byte {
+	return argon2.IDKey([]byte(nonce), []byte(seed), a.Time, a.MemoryKiB, a.Threads, digestLength)
+}
+
+type Scrypt struct {
+	N int
+	R int
+	P int
+}
+
+func (s Scrypt) ID() string {
+	return fmt.Sprintf("%s.n%d.r%d.p%d", AlgorithmScrypt, s.N, s.R, s.P)
+}
+
+func (s Scrypt) Digest(seed, nonce string) []byte {
+	digest, err := scrypt.Key([]byte(nonce), []byte(seed), s.N, s.R, s.P, digestLength)
+	if err != nil {
+		return nil
+	}
+	return digest
+}
+
+func NewAlgorithm(name string, argon2id Argon2id, scrypt Scrypt) (Algorithm, error) {
+	var a Algorithm
+	switch name {
+	case "", AlgorithmSHA256:
+		a = SHA256{}
+	case AlgorithmArgon2id:
+		a = argon2id
+	case AlgorithmScrypt:
+		a = scrypt
+	default:
+		return nil, fmt.Errorf("%w: unknown algorithm %q", ErrInvalidAlgorithm, name)
+	}
+
+	if _, err := ParseAlgorithm(a.ID()); err != nil {
+		return nil, fmt.Errorf("%w: %s has out of range parameters", ErrInvalidAlgorithm, a.ID())
+	}
+
+	return a, nil
+}
+
+func ParseAlgorithm(id string) (Algorithm, error) {
+	parts := strings.Split(id, ".")
+
+	switch parts[0] {
+	case AlgorithmSHA256:
+		if len(parts) != 1 {
+			return nil, ErrInvalidAlgorithm
+		}
+		return SHA256{}, nil
+	case AlgorithmArgon2id:
+		params, err := parseParams(parts[1:], "t", "m", "p")
+		if err != nil {
+			return nil, err
+		}
+		t, m, p := params[0], params[1], params[2]
+		if t < 1 || t > 10 || m < 8*p || m > 256*1024 || p < 1 || p > 16 {
+			return nil, ErrInvalidAlgorithm
+		}
+		return Argon2id{Time: uint32(t), MemoryKiB: uint32(m), Threads: uint8(p)}, nil
+	case AlgorithmScrypt:
+		params, err := parseParams(parts[1:], "n", "r", "p")
+		if err != nil {
+			return nil, err
+		}
+		n, r, p := params[0], params[1], params[2]
+		if n < 2 || n > 1<<18 || n&(n-1) != 0 || r < 1 || r > 32 || p < 1 || p > 16 {
+			return nil, ErrInvalidAlgorithm
+		}
+		return Scrypt{N: n, R: r, P: p}, nil
+	default:
+		return nil, ErrInvalidAlgorithm
+	}
+}
+
+func parseParams(parts []string, names ...string) ([]int, error) {
+	if len(parts) != len(names) {
+		return nil, ErrInvalidAlgorithm
+	}
+
+	params := make([]int, len(names))
+	for i, name := range names {
+		if !strings.HasPrefix(parts[i], name) {
+			return nil, ErrInvalidAlgorithm
+		}
+		v, err := strconv.Atoi(strings.TrimPrefix(parts[i], name))
+		if err != nil {
+			return nil, ErrInvalidAlgorithm
+		}
+		params[i] = v
+	}
+
+	return params, nil
+}
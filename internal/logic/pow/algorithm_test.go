@@ -0,0 +1,80 @@
+package pow
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestParseAlgorithm(t *testing.T) {
+	tests := []struct {
+		id      string
+		want    Algorithm
+		wantErr bool
+	}{
+		{id: "sha256", want: SHA256{}},
+		{id: "argon2id.t1.m8192.p1", want: Argon2id{Time: 1, MemoryKiB: 8192, Threads: 1}},
+		{id: "scrypt.n16384.r8.p1", want: Scrypt{N: 16384, R: 8, P: 1}},
+		{id: "sha256.x1", wantErr: true},
+		{id: "argon2id.t1.m8192", wantErr: true},
+		{id: "argon2id.m8192.t1.p1", wantErr: true},
+		{id: "argon2id.t0.m8192.p1", wantErr: true},
+		{id: "argon2id.t1.m1048576.p1", wantErr: true},
+		{id: "scrypt.n1000.r8.p1", wantErr: true},
+		{id: "scrypt.n16384.r8.px", wantErr: true},
+		{id: "md5", wantErr: true},
+		{id: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAlgorithm(tt.id)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseAlgorithm(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseAlgorithm(%q) = %#v, want %#v", tt.id, got, tt.want)
+		}
+		if got.ID() != tt.id {
+			t.Errorf("ID() = %q, want %q", got.ID(), tt.id)
+		}
+	}
+}
+
+func TestNewAlgorithm(t *testing.T) {
+	argon := Argon2id{Time: 1, MemoryKiB: 1024, Threads: 1}
+	sc := Scrypt{N: 1024, R: 8, P: 1}
+
+	for name, want := range map[string]Algorithm{"": SHA256{}, "sha256": SHA256{}, "argon2id": argon, "scrypt": sc} {
+		got, err := NewAlgorithm(name, argon, sc)
+		if err != nil || got != want {
+			t.Errorf("NewAlgorithm(%q) = %#v, %v; want %#v", name, got, err, want)
+		}
+	}
+
+	if _, err := NewAlgorithm("bcrypt", argon, sc); err == nil {
+		t.Error("expected error for unknown algorithm")
+	}
+	if _, err := NewAlgorithm("scrypt", argon, Scrypt{N: 1000, R: 8, P: 1}); err == nil {
+		t.Error("expected error for invalid scrypt parameters")
+	}
+}
+
+func TestAlgorithm_Digest(t *testing.T) {
+	want := sha256.Sum256([]byte("seed" + "nonce"))
+	if got := (SHA256{}).Digest("seed", "nonce"); !bytes.Equal(got, want[:]) {
+		t.Errorf("sha256 digest mismatch")
+	}
+
+	for _, a := range []Algorithm{Argon2id{Time: 1, MemoryKiB: 64, Threads: 1}, Scrypt{N: 16, R: 1, P: 1}} {
+		d1 := a.Digest("seed", "nonce")
+		d2 := a.Digest("seed", "nonce")
+		d3 := a.Digest("seed", "other")
+		if len(d1) != digestLength || !bytes.Equal(d1, d2) || bytes.Equal(d1, d3) {
+			t.Errorf("%s: digest is not deterministic or not nonce dependent", a.ID())
+		}
+	}
+}
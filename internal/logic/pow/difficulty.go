@@ -0,0 +1,64 @@
+package pow
+
+import (
+	"errors"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+const (
+	ModeHex  = "hex"
+	ModeBits = "bits"
+)
+
+var ErrInvalidDifficulty = errors.New("pow: invalid difficulty")
+
+type Difficulty struct {
+	Value int
+	Mode  string
+}
+
+func (d Difficulty) Bits() int {
+	if d.Mode == ModeBits {
+		return d.Value
+	}
+	return d.Value * 4
+}
+
+func (d Difficulty) String() string {
+	if d.Mode == ModeBits {
+		return strconv.Itoa(d.Value) + "b"
+	}
+	return strconv.Itoa(d.Value)
+}
+
+func ParseDifficulty(s string) (Difficulty, error) {
+	mode := ModeHex
+	if strings.HasSuffix(s, "b") {
+		mode = ModeBits
+		s = strings.TrimSuffix(s, "b")
+	}
+
+	value, err := strconv.Atoi(s)
+	if err != nil || value < 0 || (Difficulty{Value: value, Mode: mode}).Bits() > 256 {
+		return Difficulty{}, ErrInvalidDifficulty
+	}
+
+	return Difficulty{Value: value, Mode: mode}, nil
+}
+
+func (d Difficulty) SatisfiedBy(digest []byte) bool {
+	return LeadingZeroBits(digest) >= d.Bits()
+}
+
+func LeadingZeroBits(digest []byte) int {
+	n := 0
+	for _, b := range digest {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
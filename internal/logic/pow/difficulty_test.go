@@ -0,0 +1,85 @@
+package pow
+
+import (
+	"testing"
+)
+
+func TestLeadingZeroBits(t *testing.T) {
+	tests := []struct {
+		digest []byte
+		want   int
+	}{
+		{digest: []byte{0x80, 0x00}, want: 0},
+		{digest: []byte{0x7f}, want: 1},
+		{digest: []byte{0x01}, want: 7},
+		{digest: []byte{0x00, 0xff}, want: 8},
+		{digest: []byte{0x00, 0x0f}, want: 12},
+		{digest: []byte{0x00, 0x00, 0x01}, want: 23},
+		{digest: []byte{0x00, 0x00}, want: 16},
+		{digest: []byte{}, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := LeadingZeroBits(tt.digest); got != tt.want {
+			t.Errorf("LeadingZeroBits(%x) = %d, want %d", tt.digest, got, tt.want)
+		}
+	}
+}
+
+func TestDifficulty_SatisfiedBy(t *testing.T) {
+	digest := []byte{0x00, 0x0f, 0xff}
+
+	tests := []struct {
+		name       string
+		difficulty Difficulty
+		want       bool
+	}{
+		{name: "zero bits", difficulty: Difficulty{Value: 0, Mode: ModeBits}, want: true},
+		{name: "below boundary", difficulty: Difficulty{Value: 11, Mode: ModeBits}, want: true},
+		{name: "at boundary", difficulty: Difficulty{Value: 12, Mode: ModeBits}, want: true},
+		{name: "above boundary", difficulty: Difficulty{Value: 13, Mode: ModeBits}, want: false},
+		{name: "hex at boundary", difficulty: Difficulty{Value: 3, Mode: ModeHex}, want: true},
+		{name: "hex above boundary", difficulty: Difficulty{Value: 4, Mode: ModeHex}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.difficulty.SatisfiedBy(digest); got != tt.want {
+				t.Errorf("SatisfiedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDifficulty(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Difficulty
+		wantErr bool
+	}{
+		{in: "4", want: Difficulty{Value: 4, Mode: ModeHex}},
+		{in: "18b", want: Difficulty{Value: 18, Mode: ModeBits}},
+		{in: "256b", want: Difficulty{Value: 256, Mode: ModeBits}},
+		{in: "64", want: Difficulty{Value: 64, Mode: ModeHex}},
+		{in: "257b", wantErr: true},
+		{in: "65", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "b", wantErr: true},
+		{in: "x", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDifficulty(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDifficulty(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("ParseDifficulty(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if !tt.wantErr && got.String() != tt.in {
+			t.Errorf("String() = %q, want %q", got.String(), tt.in)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "uuid", id: New(), want: true},
+		{name: "opaque token", id: "req_01H:abc/42", want: true},
+		{name: "empty", id: "", want: false},
+		{name: "too long", id: strings.Repeat("a", maxLength+1), want: false},
+		{name: "space", id: "a b", want: false},
+		{name: "line break", id: "abc\nlevel=ERROR", want: false},
+		{name: "non ascii", id: "zażółć", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.id); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != "" {
+		t.Errorf("FromContext() = %q outside a request", got)
+	}
+
+	ctx := NewContext(context.Background(), "req-1")
+	if got := FromContext(ctx); got != "req-1" {
+		t.Errorf("FromContext() = %q, want req-1", got)
+	}
+}
@@ -0,0 +1,88 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"time"
+)
+
+var ErrInvalidPolicy = errors.New("risk: invalid policy")
+
+// Level is the escalation applied once a client's score reaches Score.
+type Level struct {
+	Score           float64
+	ExtraDifficulty int
+	SeedTtl         time.Duration
+	HardCaptcha     bool
+}
+
+// Assessment is a client's current, decayed score and the level it reached.
+type Assessment struct {
+	Score float64
+	Level Level
+}
+
+type Policy struct {
+	HalfLife       time.Duration
+	IPv4PrefixBits int
+	IPv6PrefixBits int
+	levels         []Level
+}
+
+func NewPolicy(halfLife time.Duration, ipv4PrefixBits, ipv6PrefixBits int, levels []Level) (Policy, error) {
+	if len(levels) > 0 && halfLife <= 0 {
+		return Policy{}, fmt.Errorf("%w: half-life must be positive", ErrInvalidPolicy)
+	}
+	if ipv4PrefixBits < 0 || ipv4PrefixBits > 32 || ipv6PrefixBits < 0 || ipv6PrefixBits > 128 {
+		return Policy{}, fmt.Errorf("%w: prefix length out of range", ErrInvalidPolicy)
+	}
+
+	sorted := append([]Level(nil), levels...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Score < sorted[j].Score })
+
+	return Policy{
+		HalfLife:       halfLife,
+		IPv4PrefixBits: ipv4PrefixBits,
+		IPv6PrefixBits: ipv6PrefixBits,
+		levels:         sorted,
+	}, nil
+}
+
+func (p Policy) Enabled() bool {
+	return len(p.levels) > 0
+}
+
+// LevelFor returns the highest level the score has reached, or the zero Level.
+func (p Policy) LevelFor(score float64) Level {
+	var level Level
+	for _, l := range p.levels {
+		if score < l.Score {
+			break
+		}
+		level = l
+	}
+	return level
+}
+
+// Key groups a client IP into the subnet its score is tracked under.
+func (p Policy) Key(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ip
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return fmt.Sprintf("%s/%d", v4.Mask(net.CIDRMask(p.IPv4PrefixBits, 32)), p.IPv4PrefixBits)
+	}
+	return fmt.Sprintf("%s/%d", parsed.Mask(net.CIDRMask(p.IPv6PrefixBits, 128)), p.IPv6PrefixBits)
+}
+
+// Decay returns score after elapsed time has passed.
+func (p Policy) Decay(score float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return score
+	}
+	return score * math.Pow(0.5, float64(elapsed)/float64(p.HalfLife))
+}
@@ -0,0 +1,93 @@
+package risk
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewPolicy(t *testing.T) {
+	levels := []Level{{Score: 3}}
+
+	tests := []struct {
+		name     string
+		halfLife time.Duration
+		v4, v6   int
+		levels   []Level
+		wantErr  bool
+	}{
+		{name: "valid", halfLife: time.Minute, v4: 24, v6: 64, levels: levels},
+		{name: "disabled without half-life", v4: 32, v6: 64},
+		{name: "levels without half-life", v4: 32, v6: 64, levels: levels, wantErr: true},
+		{name: "bad ipv4 prefix", halfLife: time.Minute, v4: 33, v6: 64, levels: levels, wantErr: true},
+		{name: "bad ipv6 prefix", halfLife: time.Minute, v4: 32, v6: -1, levels: levels, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicy(tt.halfLife, tt.v4, tt.v6, tt.levels); (err != nil) != tt.wantErr {
+				t.Errorf("NewPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicy_LevelFor(t *testing.T) {
+	p, _ := NewPolicy(time.Minute, 32, 64, []Level{
+		{Score: 10, ExtraDifficulty: 4, HardCaptcha: true},
+		{Score: 3, ExtraDifficulty: 2},
+	})
+
+	tests := []struct {
+		score     float64
+		wantExtra int
+		wantHard  bool
+	}{
+		{score: 0},
+		{score: 2.9},
+		{score: 3, wantExtra: 2},
+		{score: 9.5, wantExtra: 2},
+		{score: 42, wantExtra: 4, wantHard: true},
+	}
+
+	for _, tt := range tests {
+		got := p.LevelFor(tt.score)
+		if got.ExtraDifficulty != tt.wantExtra || got.HardCaptcha != tt.wantHard {
+			t.Errorf("LevelFor(%v) = %+v", tt.score, got)
+		}
+	}
+
+	if (Policy{}).Enabled() || !p.Enabled() {
+		t.Error("unexpected Enabled()")
+	}
+}
+
+func TestPolicy_Key(t *testing.T) {
+	p, _ := NewPolicy(time.Minute, 24, 48, nil)
+
+	tests := map[string]string{
+		"203.0.113.77":       "203.0.113.0/24",
+		"2001:db8:1:2:3::1":  "2001:db8:1::/48",
+		"not-an-ip":          "not-an-ip",
+		"::ffff:203.0.113.9": "203.0.113.0/24",
+	}
+	for ip, want := range tests {
+		if got := p.Key(ip); got != want {
+			t.Errorf("Key(%s) = %s, want %s", ip, got, want)
+		}
+	}
+}
+
+func TestPolicy_Decay(t *testing.T) {
+	p, _ := NewPolicy(10*time.Minute, 32, 64, nil)
+
+	if got := p.Decay(8, 10*time.Minute); math.Abs(got-4) > 1e-9 {
+		t.Errorf("one half-life: got %v, want 4", got)
+	}
+	if got := p.Decay(8, 30*time.Minute); math.Abs(got-1) > 1e-9 {
+		t.Errorf("three half-lives: got %v, want 1", got)
+	}
+	if got := p.Decay(8, -time.Minute); got != 8 {
+		t.Errorf("clock skew should not raise the score, got %v", got)
+	}
+}
@@ -0,0 +1,31 @@
+package secure
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+)
+
+var ErrMalformed = errors.New("secure: malformed value")
+
+// Equal compares two secrets in time that depends only on their lengths.
+func Equal(a, b string) bool {
+	return EqualBytes([]byte(a), []byte(b))
+}
+
+func EqualBytes(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+func DecodeHex(s string, size int) ([]byte, error) {
+	if len(s) != hex.EncodedLen(size) {
+		return nil, ErrMalformed
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	return b, nil
+}
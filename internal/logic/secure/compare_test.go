@@ -0,0 +1,57 @@
+package secure
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{a: "secret", b: "secret", want: true},
+		{a: "secret", b: "secreT"},
+		{a: "secret", b: "secret-longer"},
+		{a: "secret", b: ""},
+		{a: "", b: "", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := Equal(tt.a, tt.b); got != tt.want {
+			t.Errorf("Equal(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeHex(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []byte
+		wantErr bool
+	}{
+		{name: "valid", input: "00ff10ab", want: []byte{0x00, 0xff, 0x10, 0xab}},
+		{name: "upper case", input: "00FF10AB", want: []byte{0x00, 0xff, 0x10, 0xab}},
+		{name: "too short", input: "00ff10", wantErr: true},
+		{name: "too long", input: "00ff10ab00", wantErr: true},
+		{name: "odd length", input: "00ff10abc", wantErr: true},
+		{name: "not hex", input: "00ff10zz", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeHex(tt.input, 4)
+			if tt.wantErr {
+				if err != ErrMalformed {
+					t.Errorf("expected ErrMalformed, got %v", err)
+				}
+				return
+			}
+			if err != nil || !bytes.Equal(got, tt.want) {
+				t.Errorf("DecodeHex() = %x, %v; want %x", got, err, tt.want)
+			}
+		})
+	}
+}
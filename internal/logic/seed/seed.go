@@ -0,0 +1,137 @@
+package seed
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+)
+
+const CurrentVersion = 1
+
+var ErrMalformed = errors.New("seed: malformed seed")
+
+type Seed struct {
+	Version    int
+	Id         string
+	IssuedAt   time.Time
+	ExpiresAt  time.Time
+	Difficulty *pow.Difficulty
+	Algorithm  pow.Algorithm
+	Binding    string
+	Audience   string
+}
+
+type encodedSeed struct {
+	Version    int    `json:"v"`
+	Id         string `json:"id"`
+	IssuedAt   int64  `json:"iat"`
+	ExpiresAt  int64  `json:"exp"`
+	Difficulty string `json:"d"`
+	Algorithm  string `json:"alg"`
+	Binding    string `json:"b,omitempty"`
+	Audience   string `json:"aud,omitempty"`
+}
+
+func (s Seed) Encode() string {
+	e := encodedSeed{
+		Version:   CurrentVersion,
+		Id:        s.Id,
+		IssuedAt:  s.IssuedAt.Unix(),
+		ExpiresAt: s.ExpiresAt.Unix(),
+		Algorithm: s.Algorithm.ID(),
+		Binding:   s.Binding,
+		Audience:  s.Audience,
+	}
+	if s.Difficulty != nil {
+		e.Difficulty = s.Difficulty.String()
+	}
+
+	b, _ := json.Marshal(e)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s Seed) Expired(now time.Time, ttl time.Duration) bool {
+	if s.ExpiresAt.IsZero() {
+		return now.Sub(s.IssuedAt) > ttl
+	}
+	return now.After(s.ExpiresAt)
+}
+
+func Parse(raw string) (Seed, error) {
+	if strings.Contains(raw, ":") {
+		return parseLegacy(raw)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return Seed{}, ErrMalformed
+	}
+
+	var e encodedSeed
+	if err := json.Unmarshal(b, &e); err != nil {
+		return Seed{}, ErrMalformed
+	}
+	if e.Version < 1 || e.Version > CurrentVersion || e.Id == "" || e.IssuedAt == 0 || e.ExpiresAt < e.IssuedAt {
+		return Seed{}, ErrMalformed
+	}
+
+	d, err := pow.ParseDifficulty(e.Difficulty)
+	if err != nil {
+		return Seed{}, ErrMalformed
+	}
+	a, err := pow.ParseAlgorithm(e.Algorithm)
+	if err != nil {
+		return Seed{}, ErrMalformed
+	}
+
+	return Seed{
+		Version:    e.Version,
+		Id:         e.Id,
+		IssuedAt:   time.Unix(e.IssuedAt, 0),
+		ExpiresAt:  time.Unix(e.ExpiresAt, 0),
+		Difficulty: &d,
+		Algorithm:  a,
+		Binding:    e.Binding,
+		Audience:   e.Audience,
+	}, nil
+}
+
+func parseLegacy(raw string) (Seed, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) < 2 || len(parts) > 4 {
+		return Seed{}, ErrMalformed
+	}
+
+	ts, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Seed{}, ErrMalformed
+	}
+
+	s := Seed{
+		Id:        parts[0],
+		IssuedAt:  time.Unix(ts, 0),
+		Algorithm: pow.SHA256{},
+	}
+
+	if len(parts) >= 3 {
+		d, err := pow.ParseDifficulty(parts[2])
+		if err != nil {
+			return Seed{}, ErrMalformed
+		}
+		s.Difficulty = &d
+	}
+	if len(parts) == 4 {
+		a, err := pow.ParseAlgorithm(parts[3])
+		if err != nil {
+			return Seed{}, ErrMalformed
+		}
+		s.Algorithm = a
+	}
+
+	return s, nil
+}
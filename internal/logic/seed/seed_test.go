@@ -0,0 +1,114 @@
+package seed
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+)
+
+func encodeJSON(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func TestSeed_EncodeParse(t *testing.T) {
+	d := pow.Difficulty{Value: 18, Mode: pow.ModeBits}
+	issued := time.Unix(1700000000, 0)
+	s := Seed{
+		Id:         "abc",
+		IssuedAt:   issued,
+		ExpiresAt:  issued.Add(5 * time.Minute),
+		Difficulty: &d,
+		Algorithm:  pow.Scrypt{N: 1024, R: 8, P: 1},
+		Binding:    "client-hash",
+		Audience:   "adrianjanczenia.dev",
+	}
+
+	got, err := Parse(s.Encode())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Version != CurrentVersion || got.Id != "abc" || !got.IssuedAt.Equal(s.IssuedAt) || !got.ExpiresAt.Equal(s.ExpiresAt) {
+		t.Errorf("unexpected seed: %+v", got)
+	}
+	if got.Difficulty == nil || *got.Difficulty != d {
+		t.Errorf("unexpected difficulty: %v", got.Difficulty)
+	}
+	if got.Binding != "client-hash" || got.Audience != "adrianjanczenia.dev" {
+		t.Errorf("unexpected binding or audience: %q %q", got.Binding, got.Audience)
+	}
+	if got.Algorithm != s.Algorithm {
+		t.Errorf("unexpected algorithm: %v", got.Algorithm)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name           string
+		raw            string
+		wantErr        bool
+		wantVersion    int
+		wantDifficulty string
+		wantAlgorithm  string
+	}{
+		{name: "legacy", raw: "id:1700000000", wantAlgorithm: "sha256"},
+		{name: "legacy with difficulty", raw: "id:1700000000:4", wantDifficulty: "4", wantAlgorithm: "sha256"},
+		{name: "legacy with algorithm", raw: "id:1700000000:16b:scrypt.n1024.r8.p1", wantDifficulty: "16b", wantAlgorithm: "scrypt.n1024.r8.p1"},
+		{
+			name:           "unknown fields are ignored",
+			raw:            encodeJSON(`{"v":1,"id":"x","iat":1700000000,"exp":1700000300,"d":"16b","alg":"sha256","new":"field"}`),
+			wantVersion:    1,
+			wantDifficulty: "16b",
+			wantAlgorithm:  "sha256",
+		},
+		{name: "future version", raw: encodeJSON(`{"v":2,"id":"x","iat":1700000000,"exp":1700000300,"d":"16b","alg":"sha256"}`), wantErr: true},
+		{name: "missing id", raw: encodeJSON(`{"v":1,"iat":1700000000,"exp":1700000300,"d":"16b","alg":"sha256"}`), wantErr: true},
+		{name: "expiry before issue", raw: encodeJSON(`{"v":1,"id":"x","iat":1700000000,"exp":1,"d":"16b","alg":"sha256"}`), wantErr: true},
+		{name: "bad difficulty", raw: encodeJSON(`{"v":1,"id":"x","iat":1700000000,"exp":1700000300,"d":"x","alg":"sha256"}`), wantErr: true},
+		{name: "bad algorithm", raw: encodeJSON(`{"v":1,"id":"x","iat":1700000000,"exp":1700000300,"d":"16b","alg":"md5"}`), wantErr: true},
+		{name: "not json", raw: encodeJSON("nope"), wantErr: true},
+		{name: "not base64", raw: "not-base64!", wantErr: true},
+		{name: "legacy bad timestamp", raw: "id:soon", wantErr: true},
+		{name: "legacy too many parts", raw: "a:1:2:sha256:e", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if err != ErrMalformed {
+					t.Errorf("expected ErrMalformed, got %v", err)
+				}
+				return
+			}
+			if got.Version != tt.wantVersion {
+				t.Errorf("Version = %d, want %d", got.Version, tt.wantVersion)
+			}
+			if (got.Difficulty == nil && tt.wantDifficulty != "") || (got.Difficulty != nil && got.Difficulty.String() != tt.wantDifficulty) {
+				t.Errorf("Difficulty = %v, want %q", got.Difficulty, tt.wantDifficulty)
+			}
+			if got.Algorithm.ID() != tt.wantAlgorithm {
+				t.Errorf("Algorithm = %s, want %s", got.Algorithm.ID(), tt.wantAlgorithm)
+			}
+		})
+	}
+}
+
+func TestSeed_Expired(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	legacy := Seed{IssuedAt: now.Add(-4 * time.Minute)}
+	if legacy.Expired(now, 5*time.Minute) || !legacy.Expired(now, 3*time.Minute) {
+		t.Error("legacy seed expiry should follow the configured ttl")
+	}
+
+	versioned := Seed{IssuedAt: now.Add(-4 * time.Minute), ExpiresAt: now.Add(time.Minute)}
+	if versioned.Expired(now, time.Minute) || !versioned.Expired(now.Add(2*time.Minute), time.Hour) {
+		t.Error("versioned seed expiry should follow its own expiry")
+	}
+}
@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+func TestStartEnd(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(provider)
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, ok := Start(ctx, "ok")
+	End(ok, nil)
+	_, failed := Start(ctx, "failed")
+	End(failed, appErrors.ErrCaptchaNotFound.Wrap(errors.New("redis: nil")))
+	End(parent, nil)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want 3", len(spans))
+	}
+
+	byName := map[string]int{}
+	for i, s := range spans {
+		byName[s.Name] = i
+	}
+	okSpan, failedSpan, parentSpan := spans[byName["ok"]], spans[byName["failed"]], spans[byName["parent"]]
+
+	if okSpan.Parent.SpanID() != parentSpan.SpanContext.SpanID() || failedSpan.Parent.SpanID() != parentSpan.SpanContext.SpanID() {
+		t.Error("child spans are not parented to the span in ctx")
+	}
+	if okSpan.Status.Code != codes.Unset {
+		t.Errorf("ok span status = %v, want unset", okSpan.Status.Code)
+	}
+	if failedSpan.Status.Code != codes.Error {
+		t.Errorf("failed span status = %v, want error", failedSpan.Status.Code)
+	}
+
+	var slug string
+	for _, a := range failedSpan.Attributes {
+		if a.Key == "error.type" {
+			slug = a.Value.AsString()
+		}
+	}
+	if slug != appErrors.ErrCaptchaNotFound.Slug {
+		t.Errorf("error.type = %q, want %q", slug, appErrors.ErrCaptchaNotFound.Slug)
+	}
+}
@@ -0,0 +1,59 @@
+package audio
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+type PrepareAudioAnswerTask interface {
+	Execute(ctx context.Context, id string) (string, error)
+}
+
+type RenderAudioTask interface {
+	Execute(ctx context.Context, answer, language string) (string, string, error)
+}
+
+type Request struct {
+	CaptchaId string `json:"captchaId"`
+	Language  string `json:"language"`
+}
+
+type Response struct {
+	CaptchaId    string `json:"captchaId"`
+	CaptchaAudio string `json:"captchaAudio"`
+	Language     string `json:"language"`
+}
+
+type Process struct {
+	prepareAudioAnswerTask PrepareAudioAnswerTask
+	renderAudioTask        RenderAudioTask
+}
+
+func NewProcess(prepareAudioAnswerTask PrepareAudioAnswerTask, renderAudioTask RenderAudioTask) *Process {
+	return &Process{
+		prepareAudioAnswerTask: prepareAudioAnswerTask,
+		renderAudioTask:        renderAudioTask,
+	}
+}
+
+func (p *Process) Process(ctx context.Context, req Request) (_ *Response, err error) {
+	ctx, span := tracing.Start(ctx, "audio.Process")
+	defer func() { tracing.End(span, err) }()
+
+	answer, err := p.prepareAudioAnswerTask.Execute(ctx, req.CaptchaId)
+	if err != nil {
+		return nil, err
+	}
+
+	audio, language, err := p.renderAudioTask.Execute(ctx, answer, req.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		CaptchaId:    req.CaptchaId,
+		CaptchaAudio: audio,
+		Language:     language,
+	}, nil
+}
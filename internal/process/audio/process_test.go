@@ -0,0 +1,89 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type mockPrepareAudioAnswerTask struct {
+	executeFunc func(ctx context.Context, id string) (string, error)
+}
+
+func (m *mockPrepareAudioAnswerTask) Execute(ctx context.Context, id string) (string, error) {
+	return m.executeFunc(ctx, id)
+}
+
+type mockRenderAudioTask struct {
+	executeFunc func(ctx context.Context, answer, language string) (string, string, error)
+}
+
+func (m *mockRenderAudioTask) Execute(ctx context.Context, answer, language string) (string, string, error) {
+	return m.executeFunc(ctx, answer, language)
+}
+
+func TestProcess_Audio(t *testing.T) {
+	tests := []struct {
+		name         string
+		prepareFunc  func(context.Context, string) (string, error)
+		renderFunc   func(context.Context, string, string) (string, string, error)
+		wantErr      error
+		wantAudio    string
+		wantLanguage string
+	}{
+		{
+			name: "successful rendering",
+			prepareFunc: func(ctx context.Context, id string) (string, error) {
+				return "1234", nil
+			},
+			renderFunc: func(ctx context.Context, answer, language string) (string, string, error) {
+				return "audio-" + answer, "de", nil
+			},
+			wantAudio:    "audio-1234",
+			wantLanguage: "de",
+		},
+		{
+			name: "prepare error",
+			prepareFunc: func(ctx context.Context, id string) (string, error) {
+				return "", errors.New("not found")
+			},
+			wantErr: errors.New("not found"),
+		},
+		{
+			name: "render error",
+			prepareFunc: func(ctx context.Context, id string) (string, error) {
+				return "1234", nil
+			},
+			renderFunc: func(ctx context.Context, answer, language string) (string, string, error) {
+				return "", "", errors.New("bad language")
+			},
+			wantErr: errors.New("bad language"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProcess(
+				&mockPrepareAudioAnswerTask{executeFunc: tt.prepareFunc},
+				&mockRenderAudioTask{executeFunc: tt.renderFunc},
+			)
+
+			resp, err := p.Process(context.Background(), Request{CaptchaId: "test-id", Language: "de"})
+
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Errorf("Process() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+
+			if resp.CaptchaId != "test-id" || resp.CaptchaAudio != tt.wantAudio || resp.Language != tt.wantLanguage {
+				t.Errorf("Process() unexpected response: %+v", resp)
+			}
+		})
+	}
+}
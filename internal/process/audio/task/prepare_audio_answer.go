@@ -0,0 +1,113 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	"github.com/mojocn/base64Captcha"
+)
+
+// prepareAudioAnswerScript returns the digit answer of a captcha in one step,
+// storing ARGV[1] as audioValue when it has none and ARGV[1] is set.
+const prepareAudioAnswerScript = `
+-- prepare_audio_answer
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return {'not_found'}
+end
+
+local captcha = cjson.decode(data)
+if captcha.solved then
+	return {'already_solved'}
+end
+
+if string.match(captcha.value, '^%d+$') then
+	return {'ok', captcha.value}
+end
+if captcha.audioValue then
+	return {'ok', captcha.audioValue}
+end
+if ARGV[1] == '' then
+	return {'needs_answer'}
+end
+
+captcha.audioValue = ARGV[1]
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], cjson.encode(captcha), 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], cjson.encode(captcha))
+end
+return {'ok', captcha.audioValue}
+`
+
+type PrepareAudioAnswerRedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type PrepareAudioAnswerTask struct {
+	client PrepareAudioAnswerRedisClient
+	length int
+}
+
+func NewPrepareAudioAnswerTask(c PrepareAudioAnswerRedisClient, length int) *PrepareAudioAnswerTask {
+	return &PrepareAudioAnswerTask{
+		client: c,
+		length: length,
+	}
+}
+
+func (t *PrepareAudioAnswerTask) Execute(ctx context.Context, id string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "audio.PrepareAudioAnswerTask")
+	defer func() { tracing.End(span, err) }()
+
+	if id == "" {
+		return "", errors.ErrInvalidInput
+	}
+
+	key := fmt.Sprintf("captcha:%s", id)
+
+	values, err := t.prepare(ctx, key, "")
+	if err != nil {
+		return "", err
+	}
+	if values[0] == "needs_answer" {
+		_, digits, _ := base64Captcha.NewDriverAudio(t.length, "en").GenerateIdQuestionAnswer()
+		if values, err = t.prepare(ctx, key, digits); err != nil {
+			return "", err
+		}
+	}
+
+	switch values[0] {
+	case "ok":
+		if len(values) != 2 {
+			return "", errors.ErrInternalServerError
+		}
+		answer, ok := values[1].(string)
+		if !ok {
+			return "", errors.ErrInternalServerError
+		}
+		return answer, nil
+	case "already_solved":
+		return "", errors.ErrCaptchaSolved
+	case "not_found":
+		return "", errors.ErrCaptchaNotFound
+	default:
+		return "", errors.ErrInternalServerError
+	}
+}
+
+func (t *PrepareAudioAnswerTask) prepare(ctx context.Context, key, digits string) ([]interface{}, error) {
+	res, err := t.client.Eval(ctx, prepareAudioAnswerScript, []string{key}, digits)
+	if err != nil {
+		return nil, errors.ErrInternalServerError.Wrap(err)
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, errors.ErrInternalServerError
+	}
+	return values, nil
+}
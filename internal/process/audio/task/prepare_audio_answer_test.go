@@ -0,0 +1,101 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task/captchatest"
+)
+
+type mockPrepareAudioAnswerRedisClient struct {
+	evalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+func (m *mockPrepareAudioAnswerRedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return m.evalFunc(ctx, script, keys, args...)
+}
+
+func TestPrepareAudioAnswerTask_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("digit answer is kept", func(t *testing.T) {
+		_, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "4821", TriesLeft: 2})
+		task := NewPrepareAudioAnswerTask(client, 6)
+		answer, err := task.Execute(ctx, "id")
+		if err != nil || answer != "4821" {
+			t.Errorf("expected 4821, got %s, %v", answer, err)
+		}
+	})
+
+	t.Run("non-digit answer gets a separate audio answer keeping tries and ttl", func(t *testing.T) {
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "AB12CD", TriesLeft: 2, FailedAttempts: 1, IssuedAt: 100})
+		mr.SetTTL("captcha:id", 2*time.Minute)
+		task := NewPrepareAudioAnswerTask(client, 6)
+		answer, err := task.Execute(ctx, "id")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(answer) != 6 || answer == "AB12CD" {
+			t.Errorf("unexpected answer: %s", answer)
+		}
+
+		data, _ := mr.Get("captcha:id")
+		var state taskCaptcha.Captcha
+		json.Unmarshal([]byte(data), &state)
+		if state.Value != "AB12CD" || state.AudioValue != answer || state.TriesLeft != 2 || state.FailedAttempts != 1 || state.IssuedAt != 100 {
+			t.Errorf("unexpected state: %+v", state)
+		}
+		if mr.TTL("captcha:id") != 2*time.Minute {
+			t.Errorf("ttl changed: %v", mr.TTL("captcha:id"))
+		}
+	})
+
+	t.Run("audio answer is reused", func(t *testing.T) {
+		_, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "AB12CD", AudioValue: "123456", TriesLeft: 2})
+		task := NewPrepareAudioAnswerTask(client, 6)
+		answer, err := task.Execute(ctx, "id")
+		if err != nil || answer != "123456" {
+			t.Errorf("expected 123456, got %s, %v", answer, err)
+		}
+	})
+
+	t.Run("already solved", func(t *testing.T) {
+		_, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "4821", Solved: true})
+		task := NewPrepareAudioAnswerTask(client, 6)
+		if _, err := task.Execute(ctx, "id"); !errors.Is(err, appErrors.ErrCaptchaSolved) {
+			t.Errorf("expected ErrCaptchaSolved, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, client := captchatest.NewRedis(t)
+		task := NewPrepareAudioAnswerTask(client, 6)
+		if _, err := task.Execute(ctx, "id"); !errors.Is(err, appErrors.ErrCaptchaNotFound) {
+			t.Errorf("expected ErrCaptchaNotFound, got %v", err)
+		}
+	})
+
+	t.Run("empty id", func(t *testing.T) {
+		task := NewPrepareAudioAnswerTask(&mockPrepareAudioAnswerRedisClient{}, 6)
+		if _, err := task.Execute(ctx, ""); !errors.Is(err, appErrors.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		m := &mockPrepareAudioAnswerRedisClient{
+			evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+				return nil, errors.New("redis fail")
+			},
+		}
+		task := NewPrepareAudioAnswerTask(m, 6)
+		if _, err := task.Execute(ctx, "id"); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+}
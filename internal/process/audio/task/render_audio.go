@@ -0,0 +1,39 @@
+package task
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/audio"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	"github.com/mojocn/base64Captcha"
+)
+
+type RenderAudioTask struct {
+	defaultLanguage string
+}
+
+func NewRenderAudioTask(defaultLanguage string) *RenderAudioTask {
+	return &RenderAudioTask{
+		defaultLanguage: defaultLanguage,
+	}
+}
+
+func (t *RenderAudioTask) Execute(ctx context.Context, answer, language string) (_, _ string, err error) {
+	_, span := tracing.Start(ctx, "audio.RenderAudioTask")
+	defer func() { tracing.End(span, err) }()
+
+	if language == "" {
+		language = t.defaultLanguage
+	}
+	if !audio.Languages[language] {
+		return "", "", errors.ErrInvalidInput
+	}
+
+	item, err := base64Captcha.NewDriverAudio(len(answer), language).DrawCaptcha(answer)
+	if err != nil {
+		return "", "", errors.ErrInternalServerError.Wrap(err)
+	}
+
+	return item.EncodeB64string(), language, nil
+}
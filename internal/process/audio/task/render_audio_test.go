@@ -0,0 +1,37 @@
+package task
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+func TestRenderAudioTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	task := NewRenderAudioTask("en")
+
+	t.Run("default language", func(t *testing.T) {
+		audio, language, err := task.Execute(ctx, "1234", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if language != "en" || !strings.HasPrefix(audio, "data:audio/wav;base64,") {
+			t.Errorf("unexpected result: language=%s, audio=%.40s", language, audio)
+		}
+	})
+
+	t.Run("requested language", func(t *testing.T) {
+		_, language, err := task.Execute(ctx, "1234", "ru")
+		if err != nil || language != "ru" {
+			t.Errorf("unexpected result: language=%s, err=%v", language, err)
+		}
+	})
+
+	t.Run("unsupported language", func(t *testing.T) {
+		if _, _, err := task.Execute(ctx, "1234", "pl"); err != errors.ErrInvalidInput {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
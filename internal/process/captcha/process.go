@@ -2,109 +2,217 @@ package captcha
 
 import (
 	"context"
+	"errors"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
 )
 
 type ValidateSignatureTask interface {
-	Execute(seed, signature string) error
+	Execute(ctx context.Context, seed, signature string) error
 }
 
 type CheckSeedTimestampTask interface {
-	Execute(seed string) error
+	Execute(ctx context.Context, seed string) error
 }
 
-type ValidateUsedSeedTask interface {
+type CheckSeedAudienceTask interface {
 	Execute(ctx context.Context, seed string) error
 }
 
+type CheckSeedClientTask interface {
+	Execute(ctx context.Context, seed string, c client.Client) error
+}
+
 type VerifyPowTask interface {
-	Execute(seed, nonce string) error
+	Execute(ctx context.Context, seed, nonce string) error
 }
 
 type SaveUsedSeedTask interface {
 	Execute(ctx context.Context, seed string) error
 }
 
+type AssessRiskTask interface {
+	Execute(ctx context.Context, c client.Client) (risk.Assessment, error)
+}
+
+type RecordRiskTask interface {
+	Execute(ctx context.Context, c client.Client) error
+}
+
+type ChooseModeTask interface {
+	Execute(ctx context.Context, c client.Client, assessment risk.Assessment) string
+}
+
+type SaveSolvedCaptchaTask interface {
+	Execute(ctx context.Context, site string) (string, error)
+}
+
+type IssueTokenTask interface {
+	Execute(ctx context.Context, id, site, mode string) (string, error)
+}
+
 type GenerateCaptchaTask interface {
-	Execute() (string, string, string, error)
+	Execute(ctx context.Context) (*task.GeneratedCaptcha, error)
 }
 
 type SaveCaptchaTask interface {
-	Execute(ctx context.Context, id, value string) error
+	Execute(ctx context.Context, id, value, site string) error
 }
 
 type Request struct {
 	Seed      string `json:"seed"`
 	Signature string `json:"signature"`
 	Nonce     string `json:"nonce"`
+
+	Client client.Client `json:"-"`
 }
 
 type Response struct {
-	CaptchaId  string `json:"captchaId"`
-	CaptchaImg string `json:"captchaImg"`
+	CaptchaId   string `json:"captchaId"`
+	CaptchaImg  string `json:"captchaImg"`
+	CaptchaType string `json:"captchaType"`
+	Mode        string `json:"mode"`
+	Token       string `json:"token,omitempty"`
 }
 
 type Process struct {
 	validateSignatureTask  ValidateSignatureTask
 	checkSeedTimestampTask CheckSeedTimestampTask
-	validateUsedSeedTask   ValidateUsedSeedTask
+	checkSeedAudienceTask  CheckSeedAudienceTask
+	checkSeedClientTask    CheckSeedClientTask
 	verifyPowTask          VerifyPowTask
 	saveUsedSeedTask       SaveUsedSeedTask
+	assessRiskTask         AssessRiskTask
+	recordRiskTask         RecordRiskTask
+	chooseModeTask         ChooseModeTask
+	saveSolvedCaptchaTask  SaveSolvedCaptchaTask
+	issueTokenTask         IssueTokenTask
 	generateCaptchaTask    GenerateCaptchaTask
+	hardCaptchaTask        GenerateCaptchaTask
 	saveCaptchaTask        SaveCaptchaTask
 }
 
 func NewProcess(
 	validateSignatureTask ValidateSignatureTask,
 	checkSeedTimestampTask CheckSeedTimestampTask,
-	validateUsedSeedTask ValidateUsedSeedTask,
+	checkSeedAudienceTask CheckSeedAudienceTask,
+	checkSeedClientTask CheckSeedClientTask,
 	verifyPowTask VerifyPowTask,
 	saveUsedSeedTask SaveUsedSeedTask,
+	assessRiskTask AssessRiskTask,
+	recordRiskTask RecordRiskTask,
+	chooseModeTask ChooseModeTask,
+	saveSolvedCaptchaTask SaveSolvedCaptchaTask,
+	issueTokenTask IssueTokenTask,
 	generateCaptchaTask GenerateCaptchaTask,
+	hardCaptchaTask GenerateCaptchaTask,
 	saveCaptchaTask SaveCaptchaTask,
 ) *Process {
 	return &Process{
 		validateSignatureTask:  validateSignatureTask,
 		checkSeedTimestampTask: checkSeedTimestampTask,
-		validateUsedSeedTask:   validateUsedSeedTask,
+		checkSeedAudienceTask:  checkSeedAudienceTask,
+		checkSeedClientTask:    checkSeedClientTask,
 		verifyPowTask:          verifyPowTask,
 		saveUsedSeedTask:       saveUsedSeedTask,
+		assessRiskTask:         assessRiskTask,
+		recordRiskTask:         recordRiskTask,
+		chooseModeTask:         chooseModeTask,
+		saveSolvedCaptchaTask:  saveSolvedCaptchaTask,
+		issueTokenTask:         issueTokenTask,
 		generateCaptchaTask:    generateCaptchaTask,
+		hardCaptchaTask:        hardCaptchaTask,
 		saveCaptchaTask:        saveCaptchaTask,
 	}
 }
 
-func (p *Process) Process(ctx context.Context, req Request) (*Response, error) {
-	if err := p.validateSignatureTask.Execute(req.Seed, req.Signature); err != nil {
+func (p *Process) Process(ctx context.Context, req Request) (_ *Response, err error) {
+	ctx, span := tracing.Start(ctx, "captcha.Process")
+	defer func() { tracing.End(span, err) }()
+
+	if err := p.validateSignatureTask.Execute(ctx, req.Seed, req.Signature); err != nil {
 		return nil, err
 	}
 
-	if err := p.checkSeedTimestampTask.Execute(req.Seed); err != nil {
+	if err := p.checkSeedTimestampTask.Execute(ctx, req.Seed); err != nil {
 		return nil, err
 	}
 
-	if err := p.validateUsedSeedTask.Execute(ctx, req.Seed); err != nil {
+	if err := p.checkSeedAudienceTask.Execute(ctx, req.Seed); err != nil {
 		return nil, err
 	}
 
-	if err := p.verifyPowTask.Execute(req.Seed, req.Nonce); err != nil {
+	if err := p.checkSeedClientTask.Execute(ctx, req.Seed, req.Client); err != nil {
 		return nil, err
 	}
 
+	// The seed is claimed before the digest, so replaying one seed cannot
+	// make the server compute more than one memory-hard hash.
 	if err := p.saveUsedSeedTask.Execute(ctx, req.Seed); err != nil {
+		p.recordRisk(ctx, req.Client, err)
+		return nil, err
+	}
+
+	if err := p.verifyPowTask.Execute(ctx, req.Seed, req.Nonce); err != nil {
+		p.recordRisk(ctx, req.Client, err)
+		return nil, err
+	}
+
+	assessment, err := p.assessRiskTask.Execute(ctx, req.Client)
+	if err != nil {
 		return nil, err
 	}
 
-	id, b64s, answer, err := p.generateCaptchaTask.Execute()
+	if p.chooseModeTask.Execute(ctx, req.Client, assessment) == task.ModeInvisible {
+		return p.solveInvisible(ctx, req.Client.SiteKey)
+	}
+
+	generate := p.generateCaptchaTask
+	if assessment.Level.HardCaptcha {
+		generate = p.hardCaptchaTask
+	}
+
+	c, err := generate.Execute(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := p.saveCaptchaTask.Execute(ctx, id, answer); err != nil {
+	if err := p.saveCaptchaTask.Execute(ctx, c.Id, c.Answer, req.Client.SiteKey); err != nil {
 		return nil, err
 	}
 
 	return &Response{
-		CaptchaId:  id,
-		CaptchaImg: b64s,
+		CaptchaId:   c.Id,
+		CaptchaImg:  c.Data,
+		CaptchaType: c.Type,
+		Mode:        task.ModeChallenge,
 	}, nil
 }
+
+func (p *Process) solveInvisible(ctx context.Context, site string) (*Response, error) {
+	id, err := p.saveSolvedCaptchaTask.Execute(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.issueTokenTask.Execute(ctx, id, site, task.ModeInvisible)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		CaptchaId: id,
+		Mode:      task.ModeInvisible,
+		Token:     token,
+	}, nil
+}
+
+func (p *Process) recordRisk(ctx context.Context, c client.Client, err error) {
+	if errors.Is(err, appErrors.ErrInsufficientWork) || errors.Is(err, appErrors.ErrSeedAlreadyUsed) {
+		_ = p.recordRiskTask.Execute(ctx, c)
+	}
+}
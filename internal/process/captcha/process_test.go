@@ -4,38 +4,51 @@ import (
 	"context"
 	"errors"
 	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
 )
 
 type mockValidateSignatureTask struct {
-	executeFunc func(seed, signature string) error
+	executeFunc func(ctx context.Context, seed, signature string) error
 }
 
-func (m *mockValidateSignatureTask) Execute(seed, signature string) error {
-	return m.executeFunc(seed, signature)
+func (m *mockValidateSignatureTask) Execute(ctx context.Context, seed, signature string) error {
+	return m.executeFunc(ctx, seed, signature)
 }
 
 type mockCheckSeedTimestampTask struct {
-	executeFunc func(seed string) error
+	executeFunc func(ctx context.Context, seed string) error
 }
 
-func (m *mockCheckSeedTimestampTask) Execute(seed string) error {
-	return m.executeFunc(seed)
+func (m *mockCheckSeedTimestampTask) Execute(ctx context.Context, seed string) error {
+	return m.executeFunc(ctx, seed)
 }
 
-type mockValidateUsedSeedTask struct {
+type mockCheckSeedAudienceTask struct {
 	executeFunc func(ctx context.Context, seed string) error
 }
 
-func (m *mockValidateUsedSeedTask) Execute(ctx context.Context, seed string) error {
+func (m *mockCheckSeedAudienceTask) Execute(ctx context.Context, seed string) error {
 	return m.executeFunc(ctx, seed)
 }
 
+type mockCheckSeedClientTask struct {
+	executeFunc func(ctx context.Context, seed string, c client.Client) error
+}
+
+func (m *mockCheckSeedClientTask) Execute(ctx context.Context, seed string, c client.Client) error {
+	return m.executeFunc(ctx, seed, c)
+}
+
 type mockVerifyPowTask struct {
-	executeFunc func(seed, nonce string) error
+	executeFunc func(ctx context.Context, seed, nonce string) error
 }
 
-func (m *mockVerifyPowTask) Execute(seed, nonce string) error {
-	return m.executeFunc(seed, nonce)
+func (m *mockVerifyPowTask) Execute(ctx context.Context, seed, nonce string) error {
+	return m.executeFunc(ctx, seed, nonce)
 }
 
 type mockSaveUsedSeedTask struct {
@@ -46,141 +59,362 @@ func (m *mockSaveUsedSeedTask) Execute(ctx context.Context, seed string) error {
 	return m.executeFunc(ctx, seed)
 }
 
+type mockAssessRiskTask struct {
+	executeFunc func(ctx context.Context, c client.Client) (risk.Assessment, error)
+}
+
+func (m *mockAssessRiskTask) Execute(ctx context.Context, c client.Client) (risk.Assessment, error) {
+	return m.executeFunc(ctx, c)
+}
+
+type mockRecordRiskTask struct {
+	executeFunc func(ctx context.Context, c client.Client) error
+}
+
+func (m *mockRecordRiskTask) Execute(ctx context.Context, c client.Client) error {
+	return m.executeFunc(ctx, c)
+}
+
+type mockChooseModeTask struct {
+	executeFunc func(ctx context.Context, c client.Client, assessment risk.Assessment) string
+}
+
+func (m *mockChooseModeTask) Execute(ctx context.Context, c client.Client, assessment risk.Assessment) string {
+	return m.executeFunc(ctx, c, assessment)
+}
+
+type mockSaveSolvedCaptchaTask struct {
+	executeFunc func(ctx context.Context, site string) (string, error)
+}
+
+func (m *mockSaveSolvedCaptchaTask) Execute(ctx context.Context, site string) (string, error) {
+	return m.executeFunc(ctx, site)
+}
+
+type mockIssueTokenTask struct {
+	executeFunc func(ctx context.Context, id, site, mode string) (string, error)
+}
+
+func (m *mockIssueTokenTask) Execute(ctx context.Context, id, site, mode string) (string, error) {
+	return m.executeFunc(ctx, id, site, mode)
+}
+
 type mockGenerateCaptchaTask struct {
-	executeFunc func() (string, string, string, error)
+	executeFunc func(ctx context.Context) (*task.GeneratedCaptcha, error)
 }
 
-func (m *mockGenerateCaptchaTask) Execute() (string, string, string, error) {
-	return m.executeFunc()
+func (m *mockGenerateCaptchaTask) Execute(ctx context.Context) (*task.GeneratedCaptcha, error) {
+	return m.executeFunc(ctx)
 }
 
 type mockSaveCaptchaTask struct {
-	executeFunc func(ctx context.Context, id, value string) error
+	executeFunc func(ctx context.Context, id, value, site string) error
 }
 
-func (m *mockSaveCaptchaTask) Execute(ctx context.Context, id, value string) error {
-	return m.executeFunc(ctx, id, value)
+func (m *mockSaveCaptchaTask) Execute(ctx context.Context, id, value, site string) error {
+	return m.executeFunc(ctx, id, value, site)
 }
 
 func TestProcess_Captcha(t *testing.T) {
 	tests := []struct {
-		name                 string
-		validateSigFunc      func(string, string) error
-		checkTimestampFunc   func(string) error
-		validateUsedSeedFunc func(context.Context, string) error
-		verifyPowFunc        func(string, string) error
-		saveUsedSeedFunc     func(context.Context, string) error
-		generateCaptchaFunc  func() (string, string, string, error)
-		saveCaptchaFunc      func(context.Context, string, string) error
-		wantErr              error
-		wantId               string
-		wantImg              string
+		name                string
+		validateSigFunc     func(context.Context, string, string) error
+		checkTimestampFunc  func(context.Context, string) error
+		audienceErr         error
+		checkClientFunc     func(context.Context, string, client.Client) error
+		verifyPowFunc       func(context.Context, string, string) error
+		saveUsedSeedFunc    func(context.Context, string) error
+		assessment          risk.Assessment
+		riskErr             error
+		mode                string
+		saveSolvedFunc      func(context.Context, string) (string, error)
+		issueTokenFunc      func(context.Context, string, string, string) (string, error)
+		generateCaptchaFunc func(context.Context) (*task.GeneratedCaptcha, error)
+		saveCaptchaFunc     func(context.Context, string, string, string) error
+		wantErr             error
+		wantRecorded        bool
+		wantId              string
+		wantImg             string
+		wantType            string
+		wantMode            string
+		wantToken           string
 	}{
 		{
-			name:                 "successful process",
-			validateSigFunc:      func(s, sig string) error { return nil },
-			checkTimestampFunc:   func(s string) error { return nil },
-			validateUsedSeedFunc: func(ctx context.Context, s string) error { return nil },
-			verifyPowFunc:        func(s, n string) error { return nil },
-			saveUsedSeedFunc:     func(ctx context.Context, s string) error { return nil },
-			generateCaptchaFunc:  func() (string, string, string, error) { return "id-1", "img-1", "ans-1", nil },
-			saveCaptchaFunc:      func(ctx context.Context, id, val string) error { return nil },
-			wantErr:              nil,
-			wantId:               "id-1",
-			wantImg:              "img-1",
-		},
-		{
-			name:                 "signature validation error",
-			validateSigFunc:      func(s, sig string) error { return errors.New("sig error") },
-			checkTimestampFunc:   func(s string) error { return nil },
-			validateUsedSeedFunc: func(ctx context.Context, s string) error { return nil },
-			verifyPowFunc:        func(s, n string) error { return nil },
-			saveUsedSeedFunc:     func(ctx context.Context, s string) error { return nil },
-			generateCaptchaFunc:  func() (string, string, string, error) { return "", "", "", nil },
-			saveCaptchaFunc:      func(ctx context.Context, id, val string) error { return nil },
-			wantErr:              errors.New("sig error"),
-		},
-		{
-			name:                 "timestamp check error",
-			validateSigFunc:      func(s, sig string) error { return nil },
-			checkTimestampFunc:   func(s string) error { return errors.New("expired") },
-			validateUsedSeedFunc: func(ctx context.Context, s string) error { return nil },
-			verifyPowFunc:        func(s, n string) error { return nil },
-			saveUsedSeedFunc:     func(ctx context.Context, s string) error { return nil },
-			generateCaptchaFunc:  func() (string, string, string, error) { return "", "", "", nil },
-			saveCaptchaFunc:      func(ctx context.Context, id, val string) error { return nil },
-			wantErr:              errors.New("expired"),
-		},
-		{
-			name:                 "seed already used error",
-			validateSigFunc:      func(s, sig string) error { return nil },
-			checkTimestampFunc:   func(s string) error { return nil },
-			validateUsedSeedFunc: func(ctx context.Context, s string) error { return errors.New("double spend") },
-			verifyPowFunc:        func(s, n string) error { return nil },
-			saveUsedSeedFunc:     func(ctx context.Context, s string) error { return nil },
-			generateCaptchaFunc:  func() (string, string, string, error) { return "", "", "", nil },
-			saveCaptchaFunc:      func(ctx context.Context, id, val string) error { return nil },
-			wantErr:              errors.New("double spend"),
-		},
-		{
-			name:                 "pow verification error",
-			validateSigFunc:      func(s, sig string) error { return nil },
-			checkTimestampFunc:   func(s string) error { return nil },
-			validateUsedSeedFunc: func(ctx context.Context, s string) error { return nil },
-			verifyPowFunc:        func(s, n string) error { return errors.New("invalid work") },
-			saveUsedSeedFunc:     func(ctx context.Context, s string) error { return nil },
-			generateCaptchaFunc:  func() (string, string, string, error) { return "", "", "", nil },
-			saveCaptchaFunc:      func(ctx context.Context, id, val string) error { return nil },
-			wantErr:              errors.New("invalid work"),
-		},
-		{
-			name:                 "save used seed error",
-			validateSigFunc:      func(s, sig string) error { return nil },
-			checkTimestampFunc:   func(s string) error { return nil },
-			validateUsedSeedFunc: func(ctx context.Context, s string) error { return nil },
-			verifyPowFunc:        func(s, n string) error { return nil },
-			saveUsedSeedFunc:     func(ctx context.Context, s string) error { return errors.New("db error") },
-			generateCaptchaFunc:  func() (string, string, string, error) { return "", "", "", nil },
-			saveCaptchaFunc:      func(ctx context.Context, id, val string) error { return nil },
-			wantErr:              errors.New("db error"),
-		},
-		{
-			name:                 "captcha generation error",
-			validateSigFunc:      func(s, sig string) error { return nil },
-			checkTimestampFunc:   func(s string) error { return nil },
-			validateUsedSeedFunc: func(ctx context.Context, s string) error { return nil },
-			verifyPowFunc:        func(s, n string) error { return nil },
-			saveUsedSeedFunc:     func(ctx context.Context, s string) error { return nil },
-			generateCaptchaFunc:  func() (string, string, string, error) { return "", "", "", errors.New("gen fail") },
-			saveCaptchaFunc:      func(ctx context.Context, id, val string) error { return nil },
-			wantErr:              errors.New("gen fail"),
-		},
-		{
-			name:                 "captcha save error",
-			validateSigFunc:      func(s, sig string) error { return nil },
-			checkTimestampFunc:   func(s string) error { return nil },
-			validateUsedSeedFunc: func(ctx context.Context, s string) error { return nil },
-			verifyPowFunc:        func(s, n string) error { return nil },
-			saveUsedSeedFunc:     func(ctx context.Context, s string) error { return nil },
-			generateCaptchaFunc:  func() (string, string, string, error) { return "id-1", "img-1", "ans-1", nil },
-			saveCaptchaFunc:      func(ctx context.Context, id, val string) error { return errors.New("save fail") },
-			wantErr:              errors.New("save fail"),
+			name:               "successful process",
+			validateSigFunc:    func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc: func(ctx context.Context, s string) error { return nil },
+			checkClientFunc: func(ctx context.Context, s string, c client.Client) error {
+				if c.IP != "203.0.113.7" {
+					return errors.New("client not passed")
+				}
+				return nil
+			},
+			verifyPowFunc:    func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc: func(ctx context.Context, s string) error { return nil },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) {
+				return &task.GeneratedCaptcha{Id: "id-1", Data: "img-1", Answer: "ans-1", Type: task.DriverMath}, nil
+			},
+			saveCaptchaFunc: func(ctx context.Context, id, val, site string) error {
+				if site != "site-key" {
+					return errors.New("site not passed")
+				}
+				return nil
+			},
+			wantErr:  nil,
+			wantId:   "id-1",
+			wantImg:  "img-1",
+			wantType: task.DriverMath,
+			wantMode: task.ModeChallenge,
+		},
+		{
+			name:               "invisible mode",
+			validateSigFunc:    func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc: func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:    func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:      func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:   func(ctx context.Context, s string) error { return nil },
+			mode:               task.ModeInvisible,
+			saveSolvedFunc: func(ctx context.Context, site string) (string, error) {
+				if site != "site-key" {
+					return "", errors.New("site not passed")
+				}
+				return "solved-1", nil
+			},
+			issueTokenFunc: func(ctx context.Context, id, site, mode string) (string, error) {
+				return "token-" + id + "-" + site + "-" + mode, nil
+			},
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) {
+				return nil, errors.New("captcha generated in invisible mode")
+			},
+			saveCaptchaFunc: func(ctx context.Context, id, val, site string) error {
+				return errors.New("captcha saved in invisible mode")
+			},
+			wantId:    "solved-1",
+			wantMode:  task.ModeInvisible,
+			wantToken: "token-solved-1-site-key-invisible",
+		},
+		{
+			name:                "invisible mode save error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			mode:                task.ModeInvisible,
+			saveSolvedFunc:      func(ctx context.Context, site string) (string, error) { return "", errors.New("save fail") },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("save fail"),
+		},
+		{
+			name:                "invisible mode token error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			mode:                task.ModeInvisible,
+			saveSolvedFunc:      func(ctx context.Context, site string) (string, error) { return "solved-1", nil },
+			issueTokenFunc:      func(ctx context.Context, id, site, mode string) (string, error) { return "", errors.New("sign fail") },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("sign fail"),
+		},
+		{
+			name:               "hard captcha for escalated client",
+			validateSigFunc:    func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc: func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:    func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:      func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:   func(ctx context.Context, s string) error { return nil },
+			assessment:         risk.Assessment{Score: 5, Level: risk.Level{Score: 5, HardCaptcha: true}},
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) {
+				return nil, errors.New("normal generator used")
+			},
+			saveCaptchaFunc: func(ctx context.Context, id, val, site string) error {
+				if site != "site-key" {
+					return errors.New("site not passed")
+				}
+				return nil
+			},
+			wantErr:  nil,
+			wantId:   "hard-1",
+			wantImg:  "hard-img",
+			wantType: task.DriverString,
+			wantMode: task.ModeChallenge,
+		},
+		{
+			name:                "risk assessment error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			riskErr:             errors.New("risk fail"),
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("risk fail"),
+		},
+		{
+			name:                "insufficient work is recorded",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return appErrors.ErrInsufficientWork },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             appErrors.ErrInsufficientWork,
+			wantRecorded:        true,
+		},
+		{
+			name:                "reused seed is recorded",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return errors.New("digest computed for a used seed") },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return appErrors.ErrSeedAlreadyUsed },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             appErrors.ErrSeedAlreadyUsed,
+			wantRecorded:        true,
+		},
+		{
+			name:                "signature validation error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return errors.New("sig error") },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("sig error"),
+		},
+		{
+			name:                "timestamp check error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return errors.New("expired") },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("expired"),
+		},
+		{
+			name:               "audience mismatch error",
+			validateSigFunc:    func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc: func(ctx context.Context, s string) error { return nil },
+			audienceErr:        errors.New("other audience"),
+			wantErr:            errors.New("other audience"),
+		},
+		{
+			name:                "client mismatch error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return errors.New("other client") },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("other client"),
+		},
+		{
+			name:                "pow verification error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return errors.New("invalid work") },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("invalid work"),
+		},
+		{
+			name:                "seed already used error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return errors.New("double spend") },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("double spend"),
+		},
+		{
+			name:                "save used seed error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return errors.New("db error") },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return &task.GeneratedCaptcha{}, nil },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("db error"),
+		},
+		{
+			name:                "captcha generation error",
+			validateSigFunc:     func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc:  func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:     func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:       func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:    func(ctx context.Context, s string) error { return nil },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) { return nil, errors.New("gen fail") },
+			saveCaptchaFunc:     func(ctx context.Context, id, val, site string) error { return nil },
+			wantErr:             errors.New("gen fail"),
+		},
+		{
+			name:               "captcha save error",
+			validateSigFunc:    func(ctx context.Context, s, sig string) error { return nil },
+			checkTimestampFunc: func(ctx context.Context, s string) error { return nil },
+			checkClientFunc:    func(ctx context.Context, s string, c client.Client) error { return nil },
+			verifyPowFunc:      func(ctx context.Context, s, n string) error { return nil },
+			saveUsedSeedFunc:   func(ctx context.Context, s string) error { return nil },
+			generateCaptchaFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) {
+				return &task.GeneratedCaptcha{Id: "id-1", Data: "img-1", Answer: "ans-1", Type: task.DriverMath}, nil
+			},
+			saveCaptchaFunc: func(ctx context.Context, id, val, site string) error { return errors.New("save fail") },
+			wantErr:         errors.New("save fail"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			recorded := false
 			p := NewProcess(
 				&mockValidateSignatureTask{executeFunc: tt.validateSigFunc},
 				&mockCheckSeedTimestampTask{executeFunc: tt.checkTimestampFunc},
-				&mockValidateUsedSeedTask{executeFunc: tt.validateUsedSeedFunc},
+				&mockCheckSeedAudienceTask{executeFunc: func(ctx context.Context, s string) error { return tt.audienceErr }},
+				&mockCheckSeedClientTask{executeFunc: tt.checkClientFunc},
 				&mockVerifyPowTask{executeFunc: tt.verifyPowFunc},
 				&mockSaveUsedSeedTask{executeFunc: tt.saveUsedSeedFunc},
+				&mockAssessRiskTask{executeFunc: func(ctx context.Context, c client.Client) (risk.Assessment, error) {
+					return tt.assessment, tt.riskErr
+				}},
+				&mockRecordRiskTask{executeFunc: func(ctx context.Context, c client.Client) error {
+					recorded = true
+					return errors.New("redis down")
+				}},
+				&mockChooseModeTask{executeFunc: func(ctx context.Context, c client.Client, assessment risk.Assessment) string {
+					if tt.mode == "" {
+						return task.ModeChallenge
+					}
+					return tt.mode
+				}},
+				&mockSaveSolvedCaptchaTask{executeFunc: tt.saveSolvedFunc},
+				&mockIssueTokenTask{executeFunc: tt.issueTokenFunc},
 				&mockGenerateCaptchaTask{executeFunc: tt.generateCaptchaFunc},
+				&mockGenerateCaptchaTask{executeFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) {
+					return &task.GeneratedCaptcha{Id: "hard-1", Data: "hard-img", Answer: "ans", Type: task.DriverString}, nil
+				}},
 				&mockSaveCaptchaTask{executeFunc: tt.saveCaptchaFunc},
 			)
 
-			resp, err := p.Process(context.Background(), Request{Seed: "seed", Signature: "sig", Nonce: "nonce"})
+			resp, err := p.Process(context.Background(), Request{Seed: "seed", Signature: "sig", Nonce: "nonce", Client: client.Client{IP: "203.0.113.7", SiteKey: "site-key"}})
+
+			if recorded != tt.wantRecorded {
+				t.Errorf("Process() recorded risk = %v, want %v", recorded, tt.wantRecorded)
+			}
 
 			if tt.wantErr != nil {
 				if err == nil || err.Error() != tt.wantErr.Error() {
@@ -199,6 +433,15 @@ func TestProcess_Captcha(t *testing.T) {
 			if resp.CaptchaImg != tt.wantImg {
 				t.Errorf("Process() CaptchaImg = %v, want %v", resp.CaptchaImg, tt.wantImg)
 			}
+			if resp.CaptchaType != tt.wantType {
+				t.Errorf("Process() CaptchaType = %v, want %v", resp.CaptchaType, tt.wantType)
+			}
+			if resp.Mode != tt.wantMode {
+				t.Errorf("Process() Mode = %v, want %v", resp.Mode, tt.wantMode)
+			}
+			if resp.Token != tt.wantToken {
+				t.Errorf("Process() Token = %v, want %v", resp.Token, tt.wantToken)
+			}
 		})
 	}
 }
@@ -0,0 +1,49 @@
+// Package captchatest provides Redis fixtures for tests of tasks that work on
+// stored captchas.
+package captchatest
+
+import (
+	"encoding/json"
+	"testing"
+
+	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	serviceRedis "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+// Key is where NewRedisCaptcha stores its captcha, the record of id "id".
+const Key = "captcha:id"
+
+// NewRedis starts an in-memory Redis that is closed with the test.
+func NewRedis(t *testing.T) (*miniredis.Miniredis, serviceRedis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client, err := serviceRedis.NewClient("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return mr, client
+}
+
+// NewRedisCaptcha starts an in-memory Redis holding state under Key.
+func NewRedisCaptcha(t *testing.T, state taskCaptcha.Captcha) (*miniredis.Miniredis, serviceRedis.Client) {
+	t.Helper()
+	mr, client := NewRedis(t)
+	data, _ := json.Marshal(state)
+	mr.Set(Key, string(data))
+	return mr, client
+}
+
+// ReadRedisCaptcha returns the captcha stored under Key, or nil if it is gone.
+func ReadRedisCaptcha(t *testing.T, mr *miniredis.Miniredis) *taskCaptcha.Captcha {
+	t.Helper()
+	data, err := mr.Get(Key)
+	if err != nil {
+		return nil
+	}
+	var c taskCaptcha.Captcha
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		t.Fatalf("stored captcha is not valid json: %v", err)
+	}
+	return &c
+}
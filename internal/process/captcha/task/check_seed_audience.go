@@ -0,0 +1,35 @@
+package task
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+type CheckSeedAudienceTask struct {
+	audience string
+}
+
+func NewCheckSeedAudienceTask(audience string) *CheckSeedAudienceTask {
+	return &CheckSeedAudienceTask{
+		audience: audience,
+	}
+}
+
+func (t *CheckSeedAudienceTask) Execute(ctx context.Context, raw string) (err error) {
+	_, span := tracing.Start(ctx, "captcha.CheckSeedAudienceTask")
+	defer func() { tracing.End(span, err) }()
+
+	s, err := seed.Parse(raw)
+	if err != nil {
+		return errors.ErrInvalidInput.Wrap(err)
+	}
+
+	if s.Audience != "" && s.Audience != t.audience {
+		return errors.ErrSeedAudience
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
+)
+
+func TestCheckSeedAudienceTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	task := NewCheckSeedAudienceTask("adrianjanczenia.dev")
+
+	d := pow.Difficulty{Value: 16, Mode: pow.ModeBits}
+	newSeed := func(aud string) string {
+		return seed.Seed{Id: "id", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute), Difficulty: &d, Algorithm: pow.SHA256{}, Audience: aud}.Encode()
+	}
+
+	tests := []struct {
+		name    string
+		seed    string
+		wantErr error
+	}{
+		{name: "same audience", seed: newSeed("adrianjanczenia.dev")},
+		{name: "other audience", seed: newSeed("staging.adrianjanczenia.dev"), wantErr: appErrors.ErrSeedAudience},
+		{name: "seed without audience", seed: newSeed("")},
+		{name: "legacy seed", seed: "id:1700000000"},
+		{name: "malformed seed", seed: "not-a-seed", wantErr: appErrors.ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := task.Execute(ctx, tt.seed); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package task
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/secure"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+type CheckSeedClientTask struct {
+	binding client.Binding
+}
+
+func NewCheckSeedClientTask(binding client.Binding) *CheckSeedClientTask {
+	return &CheckSeedClientTask{
+		binding: binding,
+	}
+}
+
+func (t *CheckSeedClientTask) Execute(ctx context.Context, raw string, c client.Client) (err error) {
+	_, span := tracing.Start(ctx, "captcha.CheckSeedClientTask")
+	defer func() { tracing.End(span, err) }()
+
+	s, err := seed.Parse(raw)
+	if err != nil {
+		return errors.ErrInvalidInput.Wrap(err)
+	}
+
+	if s.Binding == "" || !t.binding.Enabled() {
+		return nil
+	}
+
+	if !secure.Equal(s.Binding, t.binding.Hash(c)) {
+		return errors.ErrSeedClientMismatch
+	}
+
+	return nil
+}
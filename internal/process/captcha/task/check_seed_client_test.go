@@ -0,0 +1,57 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
+)
+
+func TestCheckSeedClientTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	binding, err := client.NewBinding([]string{client.FieldIP, client.FieldSite})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	task := NewCheckSeedClientTask(binding)
+
+	issuedTo := client.Client{IP: "203.0.113.7", UserAgent: "ua-1", SiteKey: "contact"}
+	d := pow.Difficulty{Value: 16, Mode: pow.ModeBits}
+	newSeed := func(b string) string {
+		return seed.Seed{Id: "id", IssuedAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute), Difficulty: &d, Algorithm: pow.SHA256{}, Binding: b}.Encode()
+	}
+	bound := newSeed(binding.Hash(issuedTo))
+
+	tests := []struct {
+		name    string
+		seed    string
+		client  client.Client
+		wantErr error
+	}{
+		{name: "same client", seed: bound, client: issuedTo},
+		{name: "unbound field differs", seed: bound, client: client.Client{IP: "203.0.113.7", UserAgent: "ua-2", SiteKey: "contact"}},
+		{name: "different ip", seed: bound, client: client.Client{IP: "198.51.100.1", UserAgent: "ua-1", SiteKey: "contact"}, wantErr: appErrors.ErrSeedClientMismatch},
+		{name: "different site", seed: bound, client: client.Client{IP: "203.0.113.7", UserAgent: "ua-1", SiteKey: "blog"}, wantErr: appErrors.ErrSeedClientMismatch},
+		{name: "unbound seed", seed: newSeed(""), client: client.Client{IP: "198.51.100.1"}},
+		{name: "legacy seed", seed: "id:1700000000", client: client.Client{IP: "198.51.100.1"}},
+		{name: "malformed seed", seed: "not-a-seed", client: issuedTo, wantErr: appErrors.ErrInvalidInput},
+	}
+
+	disabled := NewCheckSeedClientTask(client.Binding{})
+	if err := disabled.Execute(ctx, bound, client.Client{IP: "198.51.100.1"}); err != nil {
+		t.Errorf("disabled binding: unexpected error: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := task.Execute(ctx, tt.seed, tt.client); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package task
 
 import (
-	"strconv"
-	"strings"
+	"context"
 	"time"
 
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 )
 
 type CheckSeedTimestampTask struct {
@@ -18,19 +19,16 @@ func NewCheckSeedTimestampTask(ttl int) *CheckSeedTimestampTask {
 	}
 }
 
-func (t *CheckSeedTimestampTask) Execute(seed string) error {
-	parts := strings.Split(seed, ":")
-	if len(parts) != 2 {
-		return errors.ErrInvalidInput
-	}
+func (t *CheckSeedTimestampTask) Execute(ctx context.Context, raw string) (err error) {
+	_, span := tracing.Start(ctx, "captcha.CheckSeedTimestampTask")
+	defer func() { tracing.End(span, err) }()
 
-	ts, err := strconv.ParseInt(parts[1], 10, 64)
+	s, err := seed.Parse(raw)
 	if err != nil {
-		return errors.ErrInvalidInput
+		return errors.ErrInvalidInput.Wrap(err)
 	}
 
-	issuedAt := time.Unix(ts, 0)
-	if time.Since(issuedAt) > time.Duration(t.ttlMinutes)*time.Minute {
+	if s.Expired(time.Now(), time.Duration(t.ttlMinutes)*time.Minute) {
 		return errors.ErrPowExpired
 	}
 
@@ -1,20 +1,46 @@
 package task
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
-	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
 )
 
+func versionedSeed(issuedAt, expiresAt time.Time) string {
+	d := pow.Difficulty{Value: 16, Mode: pow.ModeBits}
+	return seed.Seed{Id: "id", IssuedAt: issuedAt, ExpiresAt: expiresAt, Difficulty: &d, Algorithm: pow.SHA256{}}.Encode()
+}
+
 func TestCheckTimestampTask_Execute(t *testing.T) {
+	ctx := context.Background()
 	ttl := 5
 	task := NewCheckSeedTimestampTask(ttl)
 
 	t.Run("fresh", func(t *testing.T) {
 		seed := fmt.Sprintf("id:%d", time.Now().Unix())
-		err := task.Execute(seed)
+		err := task.Execute(ctx, seed)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("fresh with difficulty", func(t *testing.T) {
+		seed := fmt.Sprintf("id:%d:5", time.Now().Unix())
+		err := task.Execute(ctx, seed)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("fresh with algorithm", func(t *testing.T) {
+		seed := fmt.Sprintf("id:%d:16b:sha256", time.Now().Unix())
+		err := task.Execute(ctx, seed)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
@@ -23,15 +49,28 @@ func TestCheckTimestampTask_Execute(t *testing.T) {
 	t.Run("expired", func(t *testing.T) {
 		old := time.Now().Add(-10 * time.Minute).Unix()
 		seed := fmt.Sprintf("id:%d", old)
-		err := task.Execute(seed)
-		if err != errors.ErrPowExpired {
+		err := task.Execute(ctx, seed)
+		if !errors.Is(err, appErrors.ErrPowExpired) {
+			t.Errorf("expected ErrPowExpired, got %v", err)
+		}
+	})
+
+	t.Run("versioned, fresh", func(t *testing.T) {
+		if err := task.Execute(ctx, versionedSeed(time.Now(), time.Now().Add(time.Minute))); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("versioned, expiry wins over configured ttl", func(t *testing.T) {
+		issued := time.Now().Add(-2 * time.Minute)
+		if err := task.Execute(ctx, versionedSeed(issued, issued.Add(time.Minute))); !errors.Is(err, appErrors.ErrPowExpired) {
 			t.Errorf("expected ErrPowExpired, got %v", err)
 		}
 	})
 
 	t.Run("invalid format", func(t *testing.T) {
-		err := task.Execute("invalid-seed")
-		if err != errors.ErrInvalidInput {
+		err := task.Execute(ctx, "invalid-seed")
+		if !errors.Is(err, appErrors.ErrInvalidInput) {
 			t.Errorf("expected ErrInvalidInput, got %v", err)
 		}
 	})
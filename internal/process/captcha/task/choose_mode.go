@@ -0,0 +1,41 @@
+package task
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/pkg/solvetoken"
+)
+
+const (
+	ModeChallenge = solvetoken.ModeChallenge
+	ModeInvisible = solvetoken.ModeInvisible
+)
+
+type ChooseModeTask struct {
+	invisibleSites map[string]bool
+}
+
+func NewChooseModeTask(invisibleSites []string) *ChooseModeTask {
+	sites := make(map[string]bool, len(invisibleSites))
+	for _, site := range invisibleSites {
+		sites[site] = true
+	}
+
+	return &ChooseModeTask{
+		invisibleSites: sites,
+	}
+}
+
+func (t *ChooseModeTask) Execute(ctx context.Context, c client.Client, assessment risk.Assessment) string {
+	_, span := tracing.Start(ctx, "captcha.ChooseModeTask")
+	defer span.End()
+
+	if c.SiteKey == "" || !t.invisibleSites[c.SiteKey] || assessment.Score > 0 {
+		return ModeChallenge
+	}
+
+	return ModeInvisible
+}
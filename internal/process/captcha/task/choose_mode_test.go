@@ -0,0 +1,57 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+)
+
+func TestChooseModeTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	task := NewChooseModeTask([]string{"contact-form"})
+
+	tests := []struct {
+		name       string
+		client     client.Client
+		assessment risk.Assessment
+		want       string
+	}{
+		{
+			name:   "invisible site",
+			client: client.Client{IP: "203.0.113.7", SiteKey: "contact-form"},
+			want:   ModeInvisible,
+		},
+		{
+			name:   "other site",
+			client: client.Client{IP: "203.0.113.7", SiteKey: "login"},
+			want:   ModeChallenge,
+		},
+		{
+			name:   "no site key",
+			client: client.Client{IP: "203.0.113.7"},
+			want:   ModeChallenge,
+		},
+		{
+			name:       "escalated client on invisible site",
+			client:     client.Client{IP: "203.0.113.7", SiteKey: "contact-form"},
+			assessment: risk.Assessment{Score: 3.5, Level: risk.Level{Score: 3, ExtraDifficulty: 2}},
+			want:       ModeChallenge,
+		},
+		{
+			name:       "client below the first level on invisible site",
+			client:     client.Client{IP: "203.0.113.7", SiteKey: "contact-form"},
+			assessment: risk.Assessment{Score: 2.9},
+			want:       ModeChallenge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := task.Execute(ctx, tt.client, tt.assessment); got != tt.want {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
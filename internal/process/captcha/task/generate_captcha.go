@@ -1,33 +1,157 @@
 package task
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/mojocn/base64Captcha"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/audio"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 )
 
+const (
+	DriverString = "string"
+	DriverMath   = "math"
+	DriverDigit  = "digit"
+	DriverAudio  = "audio"
+)
+
+var lineOptions = map[string]int{
+	"hollow": base64Captcha.OptionShowHollowLine,
+	"slime":  base64Captcha.OptionShowSlimeLine,
+	"sine":   base64Captcha.OptionShowSineLine,
+}
+
+var fonts = map[string]bool{
+	"3Dumb.ttf":             true,
+	"ApothecaryFont.ttf":    true,
+	"Comismsh.ttf":          true,
+	"DENNEthree-dee.ttf":    true,
+	"DeborahFancyDress.ttf": true,
+	"Flim-Flam.ttf":         true,
+	"RitaSmith.ttf":         true,
+	"actionj.ttf":           true,
+	"chromohv.ttf":          true,
+	"wqy-microhei.ttc":      true,
+}
+
+type DriverOptions struct {
+	Type            string
+	Height          int
+	Width           int
+	NoiseCount      int
+	ShowLineOptions []string
+	Length          int
+	Source          string
+	Fonts           []string
+	MaxSkew         float64
+	DotCount        int
+	Language        string
+}
+
+type GenerationObserver interface {
+	ObserveCaptchaGeneration(d time.Duration)
+}
+
+type GeneratedCaptcha struct {
+	Id     string
+	Data   string
+	Answer string
+	Type   string
+}
+
 type GenerateCaptchaTask struct {
-	store base64Captcha.Store
+	store      base64Captcha.Store
+	driver     base64Captcha.Driver
+	driverType string
+	observer   GenerationObserver
 }
 
-func NewGenerateCaptchaTask() *GenerateCaptchaTask {
+func NewGenerateCaptchaTask(opts DriverOptions, observer GenerationObserver) (*GenerateCaptchaTask, error) {
+	if opts.Type == "" {
+		opts.Type = DriverString
+	}
+
+	driver, err := newDriver(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	return &GenerateCaptchaTask{
-		store: base64Captcha.DefaultMemStore,
+		store:      noopStore{},
+		driver:     driver,
+		driverType: opts.Type,
+		observer:   observer,
+	}, nil
+}
+
+func (t *GenerateCaptchaTask) Execute(ctx context.Context) (_ *GeneratedCaptcha, err error) {
+	_, span := tracing.Start(ctx, "captcha.GenerateCaptchaTask")
+	defer func() { tracing.End(span, err) }()
+
+	c := base64Captcha.NewCaptcha(t.driver, t.store)
+
+	start := time.Now()
+	id, data, answer, err := c.Generate()
+	if err != nil {
+		return nil, err
+	}
+	t.observer.ObserveCaptchaGeneration(time.Since(start))
+
+	return &GeneratedCaptcha{
+		Id:     id,
+		Data:   data,
+		Answer: answer,
+		Type:   t.driverType,
+	}, nil
+}
+
+func newDriver(opts DriverOptions) (base64Captcha.Driver, error) {
+	showLineOptions := 0
+	for _, name := range opts.ShowLineOptions {
+		option, ok := lineOptions[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown captcha line option %q", name)
+		}
+		showLineOptions |= option
+	}
+
+	for _, name := range opts.Fonts {
+		if !fonts[name] {
+			return nil, fmt.Errorf("unknown captcha font %q", name)
+		}
+	}
+
+	switch opts.Type {
+	case DriverString:
+		return base64Captcha.NewDriverString(opts.Height, opts.Width, opts.NoiseCount, showLineOptions, opts.Length, opts.Source, nil, nil, opts.Fonts), nil
+	case DriverMath:
+		return base64Captcha.NewDriverMath(opts.Height, opts.Width, opts.NoiseCount, showLineOptions, nil, nil, opts.Fonts), nil
+	case DriverDigit:
+		return base64Captcha.NewDriverDigit(opts.Height, opts.Width, opts.Length, opts.MaxSkew, opts.DotCount), nil
+	case DriverAudio:
+		if !audio.Languages[opts.Language] {
+			return nil, fmt.Errorf("unsupported captcha audio language %q", opts.Language)
+		}
+		return base64Captcha.NewDriverAudio(opts.Length, opts.Language), nil
+	default:
+		return nil, fmt.Errorf("unknown captcha driver %q", opts.Type)
 	}
 }
 
-func (t *GenerateCaptchaTask) Execute() (string, string, string, error) {
-	driver := base64Captcha.NewDriverString(
-		80,
-		240,
-		60,
-		base64Captcha.OptionShowSineLine|base64Captcha.OptionShowSlimeLine,
-		6,
-		"1234567890ABCDEFGHJKLMNOPQRSTUVWXYZ",
-		nil,
-		nil,
-		nil,
-	)
+// noopStore keeps nothing; answers are stored only in Redis by SaveCaptchaTask.
+type noopStore struct{}
+
+func (noopStore) Set(id string, value string) error {
+	return nil
+}
 
-	c := base64Captcha.NewCaptcha(driver, t.store)
+func (noopStore) Get(id string, clear bool) string {
+	return ""
+}
 
-	return c.Generate()
+func (noopStore) Verify(id, answer string, clear bool) bool {
+	return false
 }
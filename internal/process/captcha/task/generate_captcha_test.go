@@ -1,22 +1,148 @@
 package task
 
 import (
-	"fmt"
+	"context"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/mojocn/base64Captcha"
 )
 
 func TestGenerateCaptchaTask_Execute(t *testing.T) {
-	task := NewGenerateCaptchaTask()
+	ctx := context.Background()
+	tests := []struct {
+		name       string
+		opts       DriverOptions
+		wantType   string
+		wantPrefix string
+	}{
+		{
+			name: "string",
+			opts: DriverOptions{
+				Type:            DriverString,
+				Height:          80,
+				Width:           240,
+				NoiseCount:      60,
+				ShowLineOptions: []string{"sine", "slime"},
+				Length:          6,
+				Source:          "1234567890ABCDEFGHJKLMNOPQRSTUVWXYZ",
+				Fonts:           []string{"RitaSmith.ttf"},
+			},
+			wantType:   DriverString,
+			wantPrefix: "data:image/png;base64,",
+		},
+		{
+			name:       "default type",
+			opts:       DriverOptions{Height: 80, Width: 240, Length: 6, Source: "ABC"},
+			wantType:   DriverString,
+			wantPrefix: "data:image/png;base64,",
+		},
+		{
+			name:       "math",
+			opts:       DriverOptions{Type: DriverMath, Height: 80, Width: 240, NoiseCount: 10, ShowLineOptions: []string{"hollow"}},
+			wantType:   DriverMath,
+			wantPrefix: "data:image/png;base64,",
+		},
+		{
+			name:       "digit",
+			opts:       DriverOptions{Type: DriverDigit, Height: 80, Width: 240, Length: 5, MaxSkew: 0.7, DotCount: 80},
+			wantType:   DriverDigit,
+			wantPrefix: "data:image/png;base64,",
+		},
+		{
+			name:       "audio",
+			opts:       DriverOptions{Type: DriverAudio, Length: 4, Language: "en"},
+			wantType:   DriverAudio,
+			wantPrefix: "data:audio/wav;base64,",
+		},
+	}
 
-	id, b64, answer, err := task.Execute()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := NewGenerateCaptchaTask(tt.opts, &mockGenerationObserver{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			c, err := task.Execute(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Id == "" || c.Answer == "" {
+				t.Errorf("missing data: %+v", c)
+			}
+			if c.Type != tt.wantType {
+				t.Errorf("type = %s, want %s", c.Type, tt.wantType)
+			}
+			if !strings.HasPrefix(c.Data, tt.wantPrefix) {
+				t.Errorf("data has unexpected prefix: %.40s", c.Data)
+			}
+		})
+	}
+}
 
-	fmt.Printf("%s", b64)
-	fmt.Printf("\n%s\n", answer)
+func TestNewGenerateCaptchaTask_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts DriverOptions
+	}{
+		{name: "unknown driver", opts: DriverOptions{Type: "chinese"}},
+		{name: "unknown line option", opts: DriverOptions{Type: DriverString, ShowLineOptions: []string{"zigzag"}}},
+		{name: "unknown font", opts: DriverOptions{Type: DriverMath, Fonts: []string{"missing.ttf"}}},
+		{name: "unknown language", opts: DriverOptions{Type: DriverAudio, Length: 4, Language: "pl"}},
+	}
 
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGenerateCaptchaTask(tt.opts, &mockGenerationObserver{}); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestGenerateCaptchaTask_DoesNotKeepAnswers(t *testing.T) {
+	ctx := context.Background()
+	task, err := NewGenerateCaptchaTask(DriverOptions{Type: DriverDigit, Height: 80, Width: 240, Length: 5, MaxSkew: 0.7, DotCount: 80}, &mockGenerationObserver{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if id == "" || b64 == "" || answer == "" {
-		t.Errorf("missing data: id=%s, b64=%s, answer=%s", id, b64, answer)
+
+	c, err := task.Execute(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := base64Captcha.DefaultMemStore.Get(c.Id, false); got != "" {
+		t.Errorf("answer leaked into the in-memory store: %s", got)
+	}
+	if task.store.Verify(c.Id, c.Answer, false) {
+		t.Error("store must not verify answers")
+	}
+}
+
+type mockGenerationObserver struct {
+	observed []time.Duration
+}
+
+func (m *mockGenerationObserver) ObserveCaptchaGeneration(d time.Duration) {
+	m.observed = append(m.observed, d)
+}
+
+func TestGenerateCaptchaTask_ObservesGeneration(t *testing.T) {
+	ctx := context.Background()
+	observer := &mockGenerationObserver{}
+	task, err := NewGenerateCaptchaTask(DriverOptions{Type: DriverMath, Height: 80, Width: 240}, observer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := task.Execute(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(observer.observed) != 1 || observer.observed[0] <= 0 {
+		t.Errorf("observed = %v, want one positive duration", observer.observed)
 	}
 }
@@ -7,49 +7,71 @@ import (
 	"time"
 
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 )
 
 type Captcha struct {
-	Value     string `json:"value"`
-	TriesLeft int    `json:"triesLeft"`
-	Solved    bool   `json:"solved"`
+	Value          string `json:"value"`
+	AudioValue     string `json:"audioValue,omitempty"`
+	TriesLeft      int    `json:"triesLeft"`
+	Solved         bool   `json:"solved"`
+	FailedAttempts int    `json:"failedAttempts"`
+	IssuedAt       int64  `json:"issuedAt"`
+	SolvedAt       int64  `json:"solvedAt,omitempty"`
+	Refreshes      int    `json:"refreshes,omitempty"`
+	Mode           string `json:"mode,omitempty"`
+	Site           string `json:"site,omitempty"`
 }
 
 type SaveCaptchaRedisClient interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 }
 
+type CaptchaObserver interface {
+	ObserveCaptchaGenerated(mode string)
+	ObserveCaptchaSolved(mode string)
+}
+
 type SaveCaptchaTask struct {
 	client     SaveCaptchaRedisClient
 	ttlMinutes int
 	maxTries   int
+	observer   CaptchaObserver
 }
 
-func NewSaveCaptchaTask(c SaveCaptchaRedisClient, ttl, max int) *SaveCaptchaTask {
+func NewSaveCaptchaTask(c SaveCaptchaRedisClient, ttl, max int, observer CaptchaObserver) *SaveCaptchaTask {
 	return &SaveCaptchaTask{
 		client:     c,
 		ttlMinutes: ttl,
 		maxTries:   max,
+		observer:   observer,
 	}
 }
 
-func (t *SaveCaptchaTask) Execute(ctx context.Context, id, value string) error {
+func (t *SaveCaptchaTask) Execute(ctx context.Context, id, value, site string) (err error) {
+	ctx, span := tracing.Start(ctx, "captcha.SaveCaptchaTask")
+	defer func() { tracing.End(span, err) }()
+
 	captcha := Captcha{
 		Value:     value,
 		TriesLeft: t.maxTries,
 		Solved:    false,
+		IssuedAt:  time.Now().Unix(),
+		Site:      site,
 	}
 
 	data, err := json.Marshal(captcha)
 	if err != nil {
-		return errors.ErrInternalServerError
+		return errors.ErrInternalServerError.Wrap(err)
 	}
 
 	key := fmt.Sprintf("captcha:%s", id)
 
 	if err := t.client.Set(ctx, key, string(data), time.Duration(t.ttlMinutes)*time.Minute); err != nil {
-		return errors.ErrInternalServerError
+		return errors.ErrInternalServerError.Wrap(err)
 	}
 
+	t.observer.ObserveCaptchaGenerated(ModeChallenge)
+
 	return nil
 }
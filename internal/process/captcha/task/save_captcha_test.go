@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -26,10 +27,30 @@ func TestSaveCaptchaTask_Execute(t *testing.T) {
 				return nil
 			},
 		}
-		task := NewSaveCaptchaTask(m, 3, 3)
-		if err := task.Execute(ctx, "id", "answer"); err != nil {
+		observer := &mockCaptchaObserver{}
+		task := NewSaveCaptchaTask(m, 3, 3, observer)
+		if err := task.Execute(ctx, "id", "answer", "site-key"); err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
+		if len(observer.generated) != 1 || observer.generated[0] != ModeChallenge || len(observer.solved) != 0 {
+			t.Errorf("observed generated %v, solved %v", observer.generated, observer.solved)
+		}
+	})
+
+	t.Run("record", func(t *testing.T) {
+		var saved Captcha
+		m := &mockSaveCaptchaRedisClient{
+			setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+				return json.Unmarshal([]byte(value.(string)), &saved)
+			},
+		}
+		task := NewSaveCaptchaTask(m, 3, 3, &mockCaptchaObserver{})
+		if err := task.Execute(ctx, "id", "answer", "site-key"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if saved.Refreshes != 0 || saved.TriesLeft != 3 || saved.IssuedAt == 0 || saved.Site != "site-key" {
+			t.Errorf("unexpected record: %+v", saved)
+		}
 	})
 
 	t.Run("error", func(t *testing.T) {
@@ -38,8 +59,8 @@ func TestSaveCaptchaTask_Execute(t *testing.T) {
 				return errors.New("fail")
 			},
 		}
-		task := NewSaveCaptchaTask(m, 3, 3)
-		if err := task.Execute(ctx, "id", "answer"); err != appErrors.ErrInternalServerError {
+		task := NewSaveCaptchaTask(m, 3, 3, &mockCaptchaObserver{})
+		if err := task.Execute(ctx, "id", "answer", "site-key"); !errors.Is(err, appErrors.ErrInternalServerError) {
 			t.Errorf("expected ErrInternalServerError, got %v", err)
 		}
 	})
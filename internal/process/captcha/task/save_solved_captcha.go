@@ -0,0 +1,57 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	"github.com/mojocn/base64Captcha"
+)
+
+type SaveSolvedCaptchaTask struct {
+	client     SaveCaptchaRedisClient
+	ttlMinutes int
+	observer   CaptchaObserver
+}
+
+func NewSaveSolvedCaptchaTask(c SaveCaptchaRedisClient, ttl int, observer CaptchaObserver) *SaveSolvedCaptchaTask {
+	return &SaveSolvedCaptchaTask{
+		client:     c,
+		ttlMinutes: ttl,
+		observer:   observer,
+	}
+}
+
+func (t *SaveSolvedCaptchaTask) Execute(ctx context.Context, site string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "captcha.SaveSolvedCaptchaTask")
+	defer func() { tracing.End(span, err) }()
+
+	now := time.Now().Unix()
+	captcha := Captcha{
+		Solved:   true,
+		IssuedAt: now,
+		SolvedAt: now,
+		Mode:     ModeInvisible,
+		Site:     site,
+	}
+
+	data, err := json.Marshal(captcha)
+	if err != nil {
+		return "", errors.ErrInternalServerError.Wrap(err)
+	}
+
+	id := base64Captcha.RandomId()
+	key := fmt.Sprintf("captcha:%s", id)
+
+	if err := t.client.Set(ctx, key, string(data), time.Duration(t.ttlMinutes)*time.Minute); err != nil {
+		return "", errors.ErrInternalServerError.Wrap(err)
+	}
+
+	t.observer.ObserveCaptchaGenerated(ModeInvisible)
+	t.observer.ObserveCaptchaSolved(ModeInvisible)
+
+	return id, nil
+}
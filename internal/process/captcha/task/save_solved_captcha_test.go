@@ -0,0 +1,72 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+func TestSaveSolvedCaptchaTask_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		var savedKey string
+		var saved Captcha
+		var savedTtl time.Duration
+		m := &mockSaveCaptchaRedisClient{
+			setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+				savedKey, savedTtl = key, expiration
+				return json.Unmarshal([]byte(value.(string)), &saved)
+			},
+		}
+		observer := &mockCaptchaObserver{}
+		task := NewSaveSolvedCaptchaTask(m, 3, observer)
+
+		id, err := task.Execute(ctx, "site-key")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if id == "" || savedKey != "captcha:"+id {
+			t.Errorf("id = %q, key = %q", id, savedKey)
+		}
+		if !saved.Solved || saved.SolvedAt == 0 || saved.IssuedAt != saved.SolvedAt || saved.Mode != ModeInvisible || saved.Site != "site-key" {
+			t.Errorf("unexpected record: %+v", saved)
+		}
+		if savedTtl != 3*time.Minute {
+			t.Errorf("ttl = %v, want %v", savedTtl, 3*time.Minute)
+		}
+		if !reflect.DeepEqual(observer.generated, []string{ModeInvisible}) || !reflect.DeepEqual(observer.solved, []string{ModeInvisible}) {
+			t.Errorf("observed generated %v, solved %v", observer.generated, observer.solved)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		m := &mockSaveCaptchaRedisClient{
+			setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+				return errors.New("fail")
+			},
+		}
+		task := NewSaveSolvedCaptchaTask(m, 3, &mockCaptchaObserver{})
+		if _, err := task.Execute(ctx, "site-key"); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+}
+
+type mockCaptchaObserver struct {
+	generated []string
+	solved    []string
+}
+
+func (m *mockCaptchaObserver) ObserveCaptchaGenerated(mode string) {
+	m.generated = append(m.generated, mode)
+}
+
+func (m *mockCaptchaObserver) ObserveCaptchaSolved(mode string) {
+	m.solved = append(m.solved, mode)
+}
@@ -6,10 +6,11 @@ import (
 	"time"
 
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 )
 
 type SaveUsedSeedRedisClient interface {
-	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
 }
 
 type SaveUsedSeedTask struct {
@@ -24,12 +25,19 @@ func NewMarkSeedUsedTask(c SaveUsedSeedRedisClient, ttl int) *SaveUsedSeedTask {
 	}
 }
 
-func (t *SaveUsedSeedTask) Execute(ctx context.Context, seed string) error {
+// Execute claims the seed with a single SETNX, so only one submission wins.
+func (t *SaveUsedSeedTask) Execute(ctx context.Context, seed string) (err error) {
+	ctx, span := tracing.Start(ctx, "captcha.SaveUsedSeedTask")
+	defer func() { tracing.End(span, err) }()
+
 	key := fmt.Sprintf("pow:%s", seed)
 
-	err := t.client.Set(ctx, key, "1", time.Duration(t.ttl)*time.Minute)
+	ok, err := t.client.SetNX(ctx, key, "1", time.Duration(t.ttl)*time.Minute)
 	if err != nil {
-		return errors.ErrInternalServerError
+		return errors.ErrInternalServerError.Wrap(err)
+	}
+	if !ok {
+		return errors.ErrSeedAlreadyUsed
 	}
 
 	return nil
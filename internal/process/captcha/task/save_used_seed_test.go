@@ -3,27 +3,30 @@ package task
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	serviceRedis "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/redis"
+	"github.com/alicebob/miniredis/v2"
 )
 
 type mockSaveUsedSeedRedisClient struct {
-	setFunc func(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	setNXFunc func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
 }
 
-func (m *mockSaveUsedSeedRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return m.setFunc(ctx, key, value, expiration)
+func (m *mockSaveUsedSeedRedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return m.setNXFunc(ctx, key, value, expiration)
 }
 
 func TestSaveUsedSeedTask_Execute(t *testing.T) {
 	ctx := context.Background()
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("new seed", func(t *testing.T) {
 		m := &mockSaveUsedSeedRedisClient{
-			setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-				return nil
+			setNXFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+				return true, nil
 			},
 		}
 		task := NewMarkSeedUsedTask(m, 5)
@@ -32,15 +35,64 @@ func TestSaveUsedSeedTask_Execute(t *testing.T) {
 		}
 	})
 
+	t.Run("already used", func(t *testing.T) {
+		m := &mockSaveUsedSeedRedisClient{
+			setNXFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+				return false, nil
+			},
+		}
+		task := NewMarkSeedUsedTask(m, 5)
+		if err := task.Execute(ctx, "seed"); !errors.Is(err, appErrors.ErrSeedAlreadyUsed) {
+			t.Errorf("expected ErrSeedAlreadyUsed, got %v", err)
+		}
+	})
+
 	t.Run("error", func(t *testing.T) {
 		m := &mockSaveUsedSeedRedisClient{
-			setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-				return errors.New("fail")
+			setNXFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+				return false, errors.New("fail")
 			},
 		}
 		task := NewMarkSeedUsedTask(m, 5)
-		if err := task.Execute(ctx, "seed"); err != appErrors.ErrInternalServerError {
+		if err := task.Execute(ctx, "seed"); !errors.Is(err, appErrors.ErrInternalServerError) {
 			t.Errorf("expected ErrInternalServerError, got %v", err)
 		}
 	})
+
+	t.Run("concurrent submissions", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		client, err := serviceRedis.NewClient("redis://" + mr.Addr())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		task := NewMarkSeedUsedTask(client, 5)
+
+		const n = 50
+		results := make(chan error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results <- task.Execute(ctx, "shared-seed")
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		var succeeded, rejected int
+		for err := range results {
+			switch err {
+			case nil:
+				succeeded++
+			case appErrors.ErrSeedAlreadyUsed:
+				rejected++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		if succeeded != 1 || rejected != n-1 {
+			t.Errorf("expected 1 success and %d rejections, got %d and %d", n-1, succeeded, rejected)
+		}
+	})
 }
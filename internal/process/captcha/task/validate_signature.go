@@ -1,31 +1,58 @@
 package task
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
-	"encoding/hex"
+	"strings"
 
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/keyring"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/secure"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 )
 
 type ValidateSignatureTask struct {
-	hmacSecret string
+	keys *keyring.Keyring
 }
 
-func NewValidateSignatureTask(hmacSecret string) *ValidateSignatureTask {
+func NewValidateSignatureTask(keys *keyring.Keyring) *ValidateSignatureTask {
 	return &ValidateSignatureTask{
-		hmacSecret: hmacSecret,
+		keys: keys,
 	}
 }
 
-func (t *ValidateSignatureTask) Execute(seed, signature string) error {
-	h := hmac.New(sha256.New, []byte(t.hmacSecret))
-	h.Write([]byte(seed))
-	expected := hex.EncodeToString(h.Sum(nil))
+func (t *ValidateSignatureTask) Execute(ctx context.Context, seed, signature string) (err error) {
+	_, span := tracing.Start(ctx, "captcha.ValidateSignatureTask")
+	defer func() { tracing.End(span, err) }()
 
-	if expected != signature {
-		return errors.ErrInvalidSignature
+	keys := t.keys.Verifiers()
+	keyId, encoded, found := strings.Cut(signature, ".")
+	if !found {
+		encoded = signature
 	}
 
-	return nil
+	sig, err := secure.DecodeHex(encoded, sha256.Size)
+	if err != nil {
+		return errors.ErrInvalidInput.Wrap(err)
+	}
+
+	if found {
+		key, ok := t.keys.Lookup(keyId)
+		if !ok {
+			return errors.ErrInvalidSignature
+		}
+		keys = []keyring.Key{key}
+	}
+
+	for _, key := range keys {
+		h := hmac.New(sha256.New, []byte(key.Secret))
+		h.Write([]byte(seed))
+
+		if secure.EqualBytes(h.Sum(nil), sig) {
+			return nil
+		}
+	}
+
+	return errors.ErrInvalidSignature
 }
@@ -1,34 +1,61 @@
 package task
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"testing"
+	"time"
 
-	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/keyring"
 )
 
-func TestValidateSignatureTask_Execute(t *testing.T) {
-	secret := "secret"
-	task := NewValidateSignatureTask(secret)
-	seed := "uuid:12345678"
-
+func sign(secret, seed string) string {
 	h := hmac.New(sha256.New, []byte(secret))
 	h.Write([]byte(seed))
-	validSig := hex.EncodeToString(h.Sum(nil))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func TestValidateSignatureTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	keys, err := keyring.New([]keyring.Key{
+		{Id: "expired", Secret: "secret-0", RetiredAt: time.Now().Add(-time.Hour)},
+		{Id: "previous", Secret: "secret-1", RetiredAt: time.Now()},
+		{Id: "current", Secret: "secret-2"},
+	}, "current", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	task := NewValidateSignatureTask(keys)
+	seed := "uuid:12345678"
 
-	t.Run("valid", func(t *testing.T) {
-		err := task.Execute(seed, validSig)
-		if err != nil {
-			t.Errorf("unexpected error: %v", err)
-		}
-	})
+	tests := []struct {
+		name      string
+		signature string
+		wantErr   error
+	}{
+		{name: "active key", signature: "current." + sign("secret-2", seed)},
+		{name: "retired key within grace", signature: "previous." + sign("secret-1", seed)},
+		{name: "legacy signature without key id", signature: sign("secret-1", seed)},
+		{name: "retired key past grace", signature: "expired." + sign("secret-0", seed), wantErr: appErrors.ErrInvalidSignature},
+		{name: "legacy signature from expired key", signature: sign("secret-0", seed), wantErr: appErrors.ErrInvalidSignature},
+		{name: "wrong key id", signature: "previous." + sign("secret-2", seed), wantErr: appErrors.ErrInvalidSignature},
+		{name: "unknown key id", signature: "other." + sign("secret-2", seed), wantErr: appErrors.ErrInvalidSignature},
+		{name: "wrong signature", signature: "current." + sign("secret-3", seed), wantErr: appErrors.ErrInvalidSignature},
+		{name: "malformed hex", signature: "current.wrong-sig", wantErr: appErrors.ErrInvalidInput},
+		{name: "malformed legacy hex", signature: "wrong-sig", wantErr: appErrors.ErrInvalidInput},
+		{name: "wrong length", signature: "current." + sign("secret-2", seed)[:62], wantErr: appErrors.ErrInvalidInput},
+		{name: "empty", signature: "", wantErr: appErrors.ErrInvalidInput},
+	}
 
-	t.Run("invalid", func(t *testing.T) {
-		err := task.Execute(seed, "wrong-sig")
-		if err != errors.ErrInvalidSignature {
-			t.Errorf("expected ErrInvalidSignature, got %v", err)
-		}
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := task.Execute(ctx, seed, tt.signature); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
 }
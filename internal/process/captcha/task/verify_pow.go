@@ -1,30 +1,49 @@
 package task
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-	"strings"
+	"context"
+	"time"
 
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 )
 
+type PowObserver interface {
+	ObservePowVerification(d time.Duration)
+}
+
 type VerifyPowTask struct {
-	difficulty int
+	difficulty pow.Difficulty
+	observer   PowObserver
 }
 
-func NewVerifyPowTask(d int) *VerifyPowTask {
+func NewVerifyPowTask(d pow.Difficulty, observer PowObserver) *VerifyPowTask {
 	return &VerifyPowTask{
 		difficulty: d,
+		observer:   observer,
 	}
 }
 
-func (t *VerifyPowTask) Execute(seed, nonce string) error {
-	data := seed + nonce
-	hash := sha256.Sum256([]byte(data))
-	hashStr := hex.EncodeToString(hash[:])
+func (t *VerifyPowTask) Execute(ctx context.Context, raw, nonce string) (err error) {
+	_, span := tracing.Start(ctx, "captcha.VerifyPowTask")
+	defer func() { tracing.End(span, err) }()
+
+	s, err := seed.Parse(raw)
+	if err != nil {
+		return errors.ErrInvalidInput.Wrap(err)
+	}
+
+	difficulty := t.difficulty
+	if s.Difficulty != nil {
+		difficulty = *s.Difficulty
+	}
 
-	prefix := strings.Repeat("0", t.difficulty)
-	if !strings.HasPrefix(hashStr, prefix) {
+	start := time.Now()
+	digest := s.Algorithm.Digest(raw, nonce)
+	t.observer.ObservePowVerification(time.Since(start))
+	if !difficulty.SatisfiedBy(digest) {
 		return errors.ErrInsufficientWork
 	}
 
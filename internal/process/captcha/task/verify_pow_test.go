@@ -1,47 +1,165 @@
 package task
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
+	"errors"
 	"strconv"
-	"strings"
 	"testing"
+	"time"
 
-	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
 )
 
+func findNonce(t *testing.T, seed string, zeroBits int) string {
+	t.Helper()
+	return findAlgorithmNonce(t, pow.SHA256{}, seed, zeroBits)
+}
+
+func findAlgorithmNonce(t *testing.T, a pow.Algorithm, seed string, zeroBits int) string {
+	t.Helper()
+	for i := 0; i < 10000000; i++ {
+		n := strconv.Itoa(i)
+		if pow.LeadingZeroBits(a.Digest(seed, n)) == zeroBits {
+			return n
+		}
+	}
+	t.Fatal("could not find valid nonce for test")
+	return ""
+}
+
 func TestVerifyPowTask_Execute(t *testing.T) {
-	difficulty := 4
-	task := NewVerifyPowTask(difficulty)
-	seed := "test-seed"
-
-	t.Run("valid work", func(t *testing.T) {
-		var foundNonce string
-		prefix := strings.Repeat("0", difficulty)
-
-		for i := 0; i < 1000000; i++ {
-			n := strconv.Itoa(i)
-			hash := sha256.Sum256([]byte(seed + n))
-			if strings.HasPrefix(hex.EncodeToString(hash[:]), prefix) {
-				foundNonce = n
-				break
+	ctx := context.Background()
+	task := NewVerifyPowTask(pow.Difficulty{Value: 4, Mode: pow.ModeHex}, &mockPowObserver{})
+
+	t.Run("valid work, legacy seed", func(t *testing.T) {
+		seed := "id:1700000000"
+		nonce := findNonce(t, seed, 16)
+		if err := task.Execute(ctx, seed, nonce); err != nil {
+			t.Errorf("unexpected error for nonce %s: %v", nonce, err)
+		}
+	})
+
+	t.Run("hex difficulty from seed", func(t *testing.T) {
+		seed := "id:1700000000:2"
+		if err := task.Execute(ctx, seed, findNonce(t, seed, 8)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := task.Execute(ctx, seed, findNonce(t, seed, 7)); !errors.Is(err, appErrors.ErrInsufficientWork) {
+			t.Errorf("expected ErrInsufficientWork, got %v", err)
+		}
+	})
+
+	t.Run("bit difficulty boundaries", func(t *testing.T) {
+		seed := "id:1700000000:10b"
+		tests := []struct {
+			zeroBits int
+			wantErr  error
+		}{
+			{zeroBits: 9, wantErr: appErrors.ErrInsufficientWork},
+			{zeroBits: 10, wantErr: nil},
+			{zeroBits: 11, wantErr: nil},
+		}
+		for _, tt := range tests {
+			nonce := findNonce(t, seed, tt.zeroBits)
+			if err := task.Execute(ctx, seed, nonce); !errors.Is(err, tt.wantErr) {
+				t.Errorf("%d zero bits: expected %v, got %v", tt.zeroBits, tt.wantErr, err)
 			}
 		}
+	})
 
-		if foundNonce == "" {
-			t.Fatal("could not find valid nonce for test")
+	t.Run("zero bit difficulty", func(t *testing.T) {
+		if err := task.Execute(ctx, "id:1700000000:0b", "anything"); err != nil {
+			t.Errorf("unexpected error: %v", err)
 		}
+	})
 
-		err := task.Execute(seed, foundNonce)
-		if err != nil {
-			t.Errorf("unexpected error for nonce %s: %v", foundNonce, err)
+	t.Run("invalid difficulty in seed", func(t *testing.T) {
+		if err := task.Execute(ctx, "id:1700000000:x", "1"); !errors.Is(err, appErrors.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("memory-hard algorithm from seed", func(t *testing.T) {
+		a := pow.Argon2id{Time: 1, MemoryKiB: 64, Threads: 1}
+		seed := "id:1700000000:4b:" + a.ID()
+		if err := task.Execute(ctx, seed, findAlgorithmNonce(t, a, seed, 4)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := task.Execute(ctx, seed, findAlgorithmNonce(t, a, seed, 3)); !errors.Is(err, appErrors.ErrInsufficientWork) {
+			t.Errorf("expected ErrInsufficientWork, got %v", err)
+		}
+		if err := task.Execute(ctx, seed, findNonce(t, seed, 8)); err == nil {
+			t.Error("expected a sha256 solution to be rejected for an argon2id seed")
+		}
+	})
+
+	t.Run("invalid algorithm in seed", func(t *testing.T) {
+		if err := task.Execute(ctx, "id:1700000000:4b:md5", "1"); !errors.Is(err, appErrors.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+		if err := task.Execute(ctx, "id:1700000000:4b:argon2id.t1.m4194304.p1", "1"); !errors.Is(err, appErrors.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("versioned seed", func(t *testing.T) {
+		d := pow.Difficulty{Value: 6, Mode: pow.ModeBits}
+		raw := seed.Seed{
+			Id:         "id",
+			IssuedAt:   time.Now(),
+			ExpiresAt:  time.Now().Add(time.Minute),
+			Difficulty: &d,
+			Algorithm:  pow.SHA256{},
+		}.Encode()
+		if err := task.Execute(ctx, raw, findNonce(t, raw, 6)); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if err := task.Execute(ctx, raw, findNonce(t, raw, 5)); !errors.Is(err, appErrors.ErrInsufficientWork) {
+			t.Errorf("expected ErrInsufficientWork, got %v", err)
+		}
+	})
+
+	t.Run("malformed seed", func(t *testing.T) {
+		if err := task.Execute(ctx, "not-a-seed", "1"); !errors.Is(err, appErrors.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
 		}
 	})
 
 	t.Run("invalid work", func(t *testing.T) {
-		err := task.Execute(seed, "invalid-nonce-12345")
-		if err != errors.ErrInsufficientWork {
+		seed := "id:1700000000"
+		if err := task.Execute(ctx, seed, findNonce(t, seed, 3)); !errors.Is(err, appErrors.ErrInsufficientWork) {
 			t.Errorf("expected ErrInsufficientWork, got %v", err)
 		}
 	})
 }
+
+type mockPowObserver struct {
+	calls int
+}
+
+func (m *mockPowObserver) ObservePowVerification(d time.Duration) {
+	m.calls++
+}
+
+func TestVerifyPowTask_ObservesVerification(t *testing.T) {
+	ctx := context.Background()
+	observer := &mockPowObserver{}
+	task := NewVerifyPowTask(pow.Difficulty{Value: 4, Mode: pow.ModeHex}, observer)
+
+	seed := "id:1700000000"
+	if err := task.Execute(ctx, seed, findNonce(t, seed, 16)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := task.Execute(ctx, seed, findNonce(t, seed, 3)); !errors.Is(err, appErrors.ErrInsufficientWork) {
+		t.Fatalf("expected ErrInsufficientWork, got %v", err)
+	}
+	if err := task.Execute(ctx, "not-a-seed", "1"); !errors.Is(err, appErrors.ErrInvalidInput) {
+		t.Fatalf("expected ErrInvalidInput, got %v", err)
+	}
+
+	if observer.calls != 2 {
+		t.Errorf("observed %d verifications, want 2", observer.calls)
+	}
+}
@@ -2,35 +2,83 @@ package pow
 
 import (
 	"context"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 )
 
+type ChooseDifficultyTask interface {
+	Execute(ctx context.Context, extra int) (pow.Difficulty, error)
+}
+
+type AssessRiskTask interface {
+	Execute(ctx context.Context, c client.Client) (risk.Assessment, error)
+}
+
 type CreateSignedSeedTask interface {
-	Execute() (string, string, error)
+	Execute(ctx context.Context, difficulty pow.Difficulty, algorithm pow.Algorithm, c client.Client, ttl time.Duration) (string, string, error)
+}
+
+type Request struct {
+	Client client.Client
 }
 
 type Response struct {
-	Seed      string `json:"seed"`
-	Signature string `json:"signature"`
+	Seed           string `json:"seed"`
+	Signature      string `json:"signature"`
+	Difficulty     int    `json:"difficulty"`
+	DifficultyMode string `json:"difficultyMode"`
+	Algorithm      string `json:"algorithm"`
 }
 
 type Process struct {
+	chooseDifficultyTask ChooseDifficultyTask
+	assessRiskTask       AssessRiskTask
 	createSignedSeedTask CreateSignedSeedTask
+	algorithm            pow.Algorithm
 }
 
-func NewProcess(task CreateSignedSeedTask) *Process {
+func NewProcess(
+	chooseDifficultyTask ChooseDifficultyTask,
+	assessRiskTask AssessRiskTask,
+	createSignedSeedTask CreateSignedSeedTask,
+	algorithm pow.Algorithm,
+) *Process {
 	return &Process{
-		createSignedSeedTask: task,
+		chooseDifficultyTask: chooseDifficultyTask,
+		assessRiskTask:       assessRiskTask,
+		createSignedSeedTask: createSignedSeedTask,
+		algorithm:            algorithm,
 	}
 }
 
-func (p *Process) Process(ctx context.Context) (*Response, error) {
-	seed, signature, err := p.createSignedSeedTask.Execute()
+func (p *Process) Process(ctx context.Context, req Request) (_ *Response, err error) {
+	ctx, span := tracing.Start(ctx, "pow.Process")
+	defer func() { tracing.End(span, err) }()
+
+	assessment, err := p.assessRiskTask.Execute(ctx, req.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	difficulty, err := p.chooseDifficultyTask.Execute(ctx, assessment.Level.ExtraDifficulty)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, signature, err := p.createSignedSeedTask.Execute(ctx, difficulty, p.algorithm, req.Client, assessment.Level.SeedTtl)
 	if err != nil {
 		return nil, err
 	}
 
 	return &Response{
-		Seed:      seed,
-		Signature: signature,
+		Seed:           seed,
+		Signature:      signature,
+		Difficulty:     difficulty.Value,
+		DifficultyMode: difficulty.Mode,
+		Algorithm:      p.algorithm.ID(),
 	}, nil
 }
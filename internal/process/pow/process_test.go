@@ -4,36 +4,121 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
 )
 
+type mockChooseDifficultyTask struct {
+	executeFunc func(ctx context.Context, extra int) (pow.Difficulty, error)
+}
+
+func (m *mockChooseDifficultyTask) Execute(ctx context.Context, extra int) (pow.Difficulty, error) {
+	return m.executeFunc(ctx, extra)
+}
+
+type mockAssessRiskTask struct {
+	executeFunc func(ctx context.Context, c client.Client) (risk.Assessment, error)
+}
+
+func (m *mockAssessRiskTask) Execute(ctx context.Context, c client.Client) (risk.Assessment, error) {
+	return m.executeFunc(ctx, c)
+}
+
 type mockCreateSignedSeedTask struct {
-	executeFunc func() (string, string, error)
+	executeFunc func(ctx context.Context, difficulty pow.Difficulty, algorithm pow.Algorithm, c client.Client, ttl time.Duration) (string, string, error)
 }
 
-func (m *mockCreateSignedSeedTask) Execute() (string, string, error) {
-	return m.executeFunc()
+func (m *mockCreateSignedSeedTask) Execute(ctx context.Context, difficulty pow.Difficulty, algorithm pow.Algorithm, c client.Client, ttl time.Duration) (string, string, error) {
+	return m.executeFunc(ctx, difficulty, algorithm, c, ttl)
 }
 
 func TestProcess_Pow(t *testing.T) {
+	noRisk := func(ctx context.Context, c client.Client) (risk.Assessment, error) { return risk.Assessment{}, nil }
+
 	tests := []struct {
-		name     string
-		mockFunc func() (string, string, error)
-		wantSeed string
-		wantSig  string
-		wantErr  bool
+		name           string
+		difficultyFunc func(context.Context, int) (pow.Difficulty, error)
+		riskFunc       func(context.Context, client.Client) (risk.Assessment, error)
+		mockFunc       func(context.Context, pow.Difficulty, pow.Algorithm, client.Client, time.Duration) (string, string, error)
+		wantSeed       string
+		wantSig        string
+		wantDifficulty int
+		wantMode       string
+		wantAlgorithm  string
+		wantErr        bool
 	}{
 		{
 			name: "success",
-			mockFunc: func() (string, string, error) {
+			difficultyFunc: func(ctx context.Context, extra int) (pow.Difficulty, error) {
+				return pow.Difficulty{Value: 18, Mode: pow.ModeBits}, nil
+			},
+			riskFunc: noRisk,
+			mockFunc: func(ctx context.Context, difficulty pow.Difficulty, algorithm pow.Algorithm, c client.Client, ttl time.Duration) (string, string, error) {
+				if difficulty.Value != 18 || difficulty.Mode != pow.ModeBits {
+					t.Errorf("unexpected difficulty: %+v", difficulty)
+				}
+				if algorithm.ID() != "argon2id.t1.m8192.p1" {
+					t.Errorf("unexpected algorithm: %s", algorithm.ID())
+				}
+				if c.IP != "203.0.113.7" || ttl != 0 {
+					t.Errorf("unexpected client or ttl: %+v %v", c, ttl)
+				}
+				return "seed-123", "sig-123", nil
+			},
+			wantSeed:       "seed-123",
+			wantSig:        "sig-123",
+			wantDifficulty: 18,
+			wantMode:       pow.ModeBits,
+			wantAlgorithm:  "argon2id.t1.m8192.p1",
+		},
+		{
+			name: "escalated client",
+			difficultyFunc: func(ctx context.Context, extra int) (pow.Difficulty, error) {
+				return pow.Difficulty{Value: 18 + extra, Mode: pow.ModeBits}, nil
+			},
+			riskFunc: func(ctx context.Context, c client.Client) (risk.Assessment, error) {
+				return risk.Assessment{Score: 4, Level: risk.Level{ExtraDifficulty: 3, SeedTtl: time.Minute}}, nil
+			},
+			mockFunc: func(ctx context.Context, difficulty pow.Difficulty, algorithm pow.Algorithm, c client.Client, ttl time.Duration) (string, string, error) {
+				if difficulty.Value != 21 || ttl != time.Minute {
+					t.Errorf("escalation not applied: %+v %v", difficulty, ttl)
+				}
 				return "seed-123", "sig-123", nil
 			},
-			wantSeed: "seed-123",
-			wantSig:  "sig-123",
-			wantErr:  false,
+			wantSeed:       "seed-123",
+			wantSig:        "sig-123",
+			wantDifficulty: 21,
+			wantMode:       pow.ModeBits,
+			wantAlgorithm:  "argon2id.t1.m8192.p1",
+		},
+		{
+			name: "difficulty error",
+			difficultyFunc: func(ctx context.Context, extra int) (pow.Difficulty, error) {
+				return pow.Difficulty{}, errors.New("fail")
+			},
+			riskFunc: noRisk,
+			wantErr:  true,
+		},
+		{
+			name: "risk error",
+			difficultyFunc: func(ctx context.Context, extra int) (pow.Difficulty, error) {
+				return pow.Difficulty{Value: 4, Mode: pow.ModeHex}, nil
+			},
+			riskFunc: func(ctx context.Context, c client.Client) (risk.Assessment, error) {
+				return risk.Assessment{}, errors.New("fail")
+			},
+			wantErr: true,
 		},
 		{
 			name: "error",
-			mockFunc: func() (string, string, error) {
+			difficultyFunc: func(ctx context.Context, extra int) (pow.Difficulty, error) {
+				return pow.Difficulty{Value: 4, Mode: pow.ModeHex}, nil
+			},
+			riskFunc: noRisk,
+			mockFunc: func(ctx context.Context, difficulty pow.Difficulty, algorithm pow.Algorithm, c client.Client, ttl time.Duration) (string, string, error) {
 				return "", "", errors.New("fail")
 			},
 			wantErr: true,
@@ -42,14 +127,19 @@ func TestProcess_Pow(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := NewProcess(&mockCreateSignedSeedTask{executeFunc: tt.mockFunc})
-			res, err := p.Process(context.Background())
+			p := NewProcess(
+				&mockChooseDifficultyTask{executeFunc: tt.difficultyFunc},
+				&mockAssessRiskTask{executeFunc: tt.riskFunc},
+				&mockCreateSignedSeedTask{executeFunc: tt.mockFunc},
+				pow.Argon2id{Time: 1, MemoryKiB: 8192, Threads: 1},
+			)
+			res, err := p.Process(context.Background(), Request{Client: client.Client{IP: "203.0.113.7"}})
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("wantErr = %v, got %v", tt.wantErr, err)
 			}
 			if !tt.wantErr {
-				if res.Seed != tt.wantSeed || res.Signature != tt.wantSig {
+				if res.Seed != tt.wantSeed || res.Signature != tt.wantSig || res.Difficulty != tt.wantDifficulty || res.DifficultyMode != tt.wantMode || res.Algorithm != tt.wantAlgorithm {
 					t.Errorf("unexpected response: %+v", res)
 				}
 			}
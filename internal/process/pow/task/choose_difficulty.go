@@ -0,0 +1,79 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+// countRequestScript increments the counter of the current window and sets
+// its expiry on first use, so the count and the TTL can never drift apart.
+const countRequestScript = `
+-- count_request
+local count = redis.call('INCR', KEYS[1])
+if count == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return count
+`
+
+type ChooseDifficultyRedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type DifficultyPolicy struct {
+	Mode            string
+	Base            int
+	Max             int
+	Window          time.Duration
+	RequestsPerStep int
+}
+
+type ChooseDifficultyTask struct {
+	client ChooseDifficultyRedisClient
+	policy DifficultyPolicy
+}
+
+func NewChooseDifficultyTask(c ChooseDifficultyRedisClient, policy DifficultyPolicy) *ChooseDifficultyTask {
+	return &ChooseDifficultyTask{
+		client: c,
+		policy: policy,
+	}
+}
+
+func (t *ChooseDifficultyTask) Execute(ctx context.Context, extra int) (_ pow.Difficulty, err error) {
+	ctx, span := tracing.Start(ctx, "pow.ChooseDifficultyTask")
+	defer func() { tracing.End(span, err) }()
+
+	if t.policy.RequestsPerStep <= 0 || t.policy.Window <= 0 {
+		return pow.Difficulty{Value: t.capped(t.policy.Base + extra), Mode: t.policy.Mode}, nil
+	}
+
+	window := time.Now().UnixNano() / int64(t.policy.Window)
+	key := fmt.Sprintf("pow:rate:%d", window)
+
+	res, err := t.client.Eval(ctx, countRequestScript, []string{key}, t.policy.Window.Milliseconds())
+	if err != nil {
+		return pow.Difficulty{}, errors.ErrInternalServerError.Wrap(err)
+	}
+
+	count, ok := res.(int64)
+	if !ok {
+		return pow.Difficulty{}, errors.ErrInternalServerError
+	}
+
+	difficulty := t.policy.Base + int((count-1)/int64(t.policy.RequestsPerStep)) + extra
+
+	return pow.Difficulty{Value: t.capped(difficulty), Mode: t.policy.Mode}, nil
+}
+
+func (t *ChooseDifficultyTask) capped(difficulty int) int {
+	if t.policy.Max > 0 && difficulty > t.policy.Max {
+		return t.policy.Max
+	}
+	return difficulty
+}
@@ -0,0 +1,95 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	serviceRedis "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+type mockChooseDifficultyRedisClient struct {
+	evalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+func (m *mockChooseDifficultyRedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return m.evalFunc(ctx, script, keys, args...)
+}
+
+func TestChooseDifficultyTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	policy := DifficultyPolicy{Mode: pow.ModeBits, Base: 4, Max: 6, Window: time.Hour, RequestsPerStep: 10}
+
+	t.Run("rises with request rate", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		client, err := serviceRedis.NewClient("redis://" + mr.Addr())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		task := NewChooseDifficultyTask(client, policy)
+
+		want := map[int]int{1: 4, 10: 4, 11: 5, 20: 5, 21: 6, 100: 6}
+		for i := 1; i <= 100; i++ {
+			d, err := task.Execute(ctx, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if d.Mode != pow.ModeBits {
+				t.Fatalf("mode = %s, want %s", d.Mode, pow.ModeBits)
+			}
+			if w, ok := want[i]; ok && d.Value != w {
+				t.Errorf("request %d: difficulty = %d, want %d", i, d.Value, w)
+			}
+		}
+
+		for _, key := range mr.Keys() {
+			if ttl := mr.TTL(key); ttl <= 0 || ttl > time.Hour {
+				t.Errorf("key %s has unexpected ttl %v", key, ttl)
+			}
+		}
+	})
+
+	t.Run("adaptation disabled", func(t *testing.T) {
+		task := NewChooseDifficultyTask(&mockChooseDifficultyRedisClient{}, DifficultyPolicy{Mode: pow.ModeHex, Base: 4})
+		if d, err := task.Execute(ctx, 0); err != nil || d != (pow.Difficulty{Value: 4, Mode: pow.ModeHex}) {
+			t.Errorf("expected 4, nil; got %+v, %v", d, err)
+		}
+	})
+
+	t.Run("extra difficulty is capped", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		client, err := serviceRedis.NewClient("redis://" + mr.Addr())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		task := NewChooseDifficultyTask(client, policy)
+
+		if d, err := task.Execute(ctx, 1); err != nil || d.Value != 5 {
+			t.Errorf("expected 5, nil; got %+v, %v", d, err)
+		}
+		if d, err := task.Execute(ctx, 5); err != nil || d.Value != 6 {
+			t.Errorf("expected 6, nil; got %+v, %v", d, err)
+		}
+
+		disabled := NewChooseDifficultyTask(&mockChooseDifficultyRedisClient{}, DifficultyPolicy{Mode: pow.ModeHex, Base: 4, Max: 5})
+		if d, err := disabled.Execute(ctx, 3); err != nil || d.Value != 5 {
+			t.Errorf("expected 5, nil; got %+v, %v", d, err)
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		m := &mockChooseDifficultyRedisClient{
+			evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+				return nil, errors.New("redis fail")
+			},
+		}
+		task := NewChooseDifficultyTask(m, policy)
+		if _, err := task.Execute(ctx, 0); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+}
@@ -1,34 +1,61 @@
 package task
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
 	"time"
 
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/keyring"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 	"github.com/google/uuid"
 )
 
 type CreateSignedSeedTask struct {
-	hmacSecret string
+	keys       *keyring.Keyring
+	binding    client.Binding
+	audience   string
+	ttlMinutes int
 }
 
-func NewCreateSignedSeedTask(hmacSecret string) *CreateSignedSeedTask {
+func NewCreateSignedSeedTask(keys *keyring.Keyring, binding client.Binding, audience string, ttl int) *CreateSignedSeedTask {
 	return &CreateSignedSeedTask{
-		hmacSecret: hmacSecret,
+		keys:       keys,
+		binding:    binding,
+		audience:   audience,
+		ttlMinutes: ttl,
 	}
 }
 
-func (t *CreateSignedSeedTask) Execute() (string, string, error) {
-	timestamp := time.Now().Unix()
-	id := uuid.New().String()
-	seed := fmt.Sprintf("%s:%d", id, timestamp)
+func (t *CreateSignedSeedTask) Execute(ctx context.Context, difficulty pow.Difficulty, algorithm pow.Algorithm, c client.Client, ttl time.Duration) (_, _ string, err error) {
+	_, span := tracing.Start(ctx, "pow.CreateSignedSeedTask")
+	defer func() { tracing.End(span, err) }()
 
-	h := hmac.New(sha256.New, []byte(t.hmacSecret))
-	h.Write([]byte(seed))
+	lifetime := time.Duration(t.ttlMinutes) * time.Minute
+	if ttl > 0 && ttl < lifetime {
+		lifetime = ttl
+	}
+
+	now := time.Now()
+	encoded := seed.Seed{
+		Id:         uuid.New().String(),
+		IssuedAt:   now,
+		ExpiresAt:  now.Add(lifetime),
+		Difficulty: &difficulty,
+		Algorithm:  algorithm,
+		Binding:    t.binding.Hash(c),
+		Audience:   t.audience,
+	}.Encode()
+
+	key := t.keys.Active()
+	h := hmac.New(sha256.New, []byte(key.Secret))
+	h.Write([]byte(encoded))
 
-	signature := hex.EncodeToString(h.Sum(nil))
+	signature := key.Id + "." + hex.EncodeToString(h.Sum(nil))
 
-	return seed, signature, nil
+	return encoded, signature, nil
 }
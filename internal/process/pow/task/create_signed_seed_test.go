@@ -1,33 +1,89 @@
 package task
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
-	"strings"
 	"testing"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/keyring"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+	seedFormat "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/seed"
 )
 
 func TestCreateSignedSeedTask_Execute(t *testing.T) {
+	ctx := context.Background()
 	secret := "test-secret"
-	task := NewCreateSignedSeedTask(secret)
+	keys, err := keyring.New([]keyring.Key{
+		{Id: "old", Secret: "old-secret", RetiredAt: time.Now()},
+		{Id: "k2", Secret: secret},
+	}, "k2", time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	binding, err := client.NewBinding([]string{client.FieldIP})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	task := NewCreateSignedSeedTask(keys, binding, "adrianjanczenia.dev", 5)
+	c := client.Client{IP: "203.0.113.7"}
 
-	seed, signature, err := task.Execute()
+	seed, signature, err := task.Execute(ctx, pow.Difficulty{Value: 18, Mode: pow.ModeBits}, pow.Scrypt{N: 1024, R: 8, P: 1}, c, 0)
 
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	parts := strings.Split(seed, ":")
-	if len(parts) != 2 {
-		t.Errorf("invalid seed format: %s", seed)
+	parsed, err := seedFormat.Parse(seed)
+	if err != nil {
+		t.Fatalf("invalid seed format: %v", err)
+	}
+	if parsed.Version != seedFormat.CurrentVersion || parsed.Difficulty.String() != "18b" || parsed.Algorithm.ID() != "scrypt.n1024.r8.p1" {
+		t.Errorf("unexpected seed: %+v", parsed)
+	}
+	if parsed.Binding != binding.Hash(c) {
+		t.Errorf("seed not bound to client: %q", parsed.Binding)
+	}
+	if parsed.Audience != "adrianjanczenia.dev" {
+		t.Errorf("unexpected seed audience: %q", parsed.Audience)
+	}
+	if got := parsed.ExpiresAt.Sub(parsed.IssuedAt); got != 5*time.Minute {
+		t.Errorf("unexpected seed lifetime: %v", got)
 	}
 
 	h := hmac.New(sha256.New, []byte(secret))
 	h.Write([]byte(seed))
-	expected := hex.EncodeToString(h.Sum(nil))
+	expected := "k2." + hex.EncodeToString(h.Sum(nil))
 
 	if signature != expected {
 		t.Errorf("invalid signature; got %s, want %s", signature, expected)
 	}
 }
+
+func TestCreateSignedSeedTask_ExecuteShortTtl(t *testing.T) {
+	ctx := context.Background()
+	keys, _ := keyring.New([]keyring.Key{{Id: "k1", Secret: "s"}}, "k1", time.Minute)
+	task := NewCreateSignedSeedTask(keys, client.Binding{}, "adrianjanczenia.dev", 5)
+
+	tests := []struct {
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{ttl: time.Minute, want: time.Minute},
+		{ttl: time.Hour, want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		seed, _, err := task.Execute(ctx, pow.Difficulty{Value: 4}, pow.SHA256{}, client.Client{}, tt.ttl)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		parsed, _ := seedFormat.Parse(seed)
+		if got := parsed.ExpiresAt.Sub(parsed.IssuedAt); got != tt.want {
+			t.Errorf("ttl %v: lifetime = %v, want %v", tt.ttl, got, tt.want)
+		}
+	}
+}
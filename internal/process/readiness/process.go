@@ -0,0 +1,51 @@
+package readiness
+
+import (
+	"context"
+)
+
+type CheckDrainingTask interface {
+	Execute(ctx context.Context) error
+}
+
+type CheckConfigTask interface {
+	Execute(ctx context.Context) error
+}
+
+type PingRedisTask interface {
+	Execute(ctx context.Context) error
+}
+
+type Response struct {
+	Status string `json:"status"`
+}
+
+type Process struct {
+	checkDrainingTask CheckDrainingTask
+	checkConfigTask   CheckConfigTask
+	pingRedisTask     PingRedisTask
+}
+
+func NewProcess(checkDrainingTask CheckDrainingTask, checkConfigTask CheckConfigTask, pingRedisTask PingRedisTask) *Process {
+	return &Process{
+		checkDrainingTask: checkDrainingTask,
+		checkConfigTask:   checkConfigTask,
+		pingRedisTask:     pingRedisTask,
+	}
+}
+
+func (p *Process) Process(ctx context.Context) (*Response, error) {
+	if err := p.checkDrainingTask.Execute(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := p.checkConfigTask.Execute(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := p.pingRedisTask.Execute(ctx); err != nil {
+		return nil, err
+	}
+
+	return &Response{Status: "ready"}, nil
+}
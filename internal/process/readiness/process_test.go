@@ -0,0 +1,87 @@
+package readiness
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type mockCheckTask struct {
+	executeFunc func(ctx context.Context) error
+}
+
+func (m *mockCheckTask) Execute(ctx context.Context) error {
+	return m.executeFunc(ctx)
+}
+
+func TestProcess_Readiness(t *testing.T) {
+	pass := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		name         string
+		drainingFunc func(context.Context) error
+		configFunc   func(context.Context) error
+		redisFunc    func(context.Context) error
+		wantErr      error
+		wantResp     *Response
+	}{
+		{
+			name:         "ready",
+			drainingFunc: pass,
+			configFunc:   pass,
+			redisFunc:    pass,
+			wantResp:     &Response{Status: "ready"},
+		},
+		{
+			name:         "shutting down",
+			drainingFunc: func(ctx context.Context) error { return appErrors.ErrShuttingDown },
+			configFunc: func(ctx context.Context) error {
+				t.Error("config must not be checked while shutting down")
+				return nil
+			},
+			redisFunc: func(ctx context.Context) error {
+				t.Error("redis must not be pinged while shutting down")
+				return nil
+			},
+			wantErr: appErrors.ErrShuttingDown,
+		},
+		{
+			name:         "invalid config",
+			drainingFunc: pass,
+			configFunc:   func(ctx context.Context) error { return appErrors.ErrInvalidConfig },
+			redisFunc: func(ctx context.Context) error {
+				t.Error("redis must not be pinged with an invalid config")
+				return nil
+			},
+			wantErr: appErrors.ErrInvalidConfig,
+		},
+		{
+			name:         "redis unavailable",
+			drainingFunc: pass,
+			configFunc:   pass,
+			redisFunc:    func(ctx context.Context) error { return appErrors.ErrRedisUnavailable },
+			wantErr:      appErrors.ErrRedisUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProcess(
+				&mockCheckTask{executeFunc: tt.drainingFunc},
+				&mockCheckTask{executeFunc: tt.configFunc},
+				&mockCheckTask{executeFunc: tt.redisFunc},
+			)
+
+			resp, err := p.Process(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(resp, tt.wantResp) {
+				t.Errorf("Process() resp = %+v, want %+v", resp, tt.wantResp)
+			}
+		})
+	}
+}
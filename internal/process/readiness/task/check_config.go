@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type ConfigValidator interface {
+	Validate() error
+}
+
+type CheckConfigTask struct {
+	config ConfigValidator
+}
+
+func NewCheckConfigTask(c ConfigValidator) *CheckConfigTask {
+	return &CheckConfigTask{
+		config: c,
+	}
+}
+
+func (t *CheckConfigTask) Execute(ctx context.Context) error {
+	if err := t.config.Validate(); err != nil {
+		return errors.ErrInvalidConfig.Wrap(err)
+	}
+	return nil
+}
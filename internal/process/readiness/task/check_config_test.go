@@ -0,0 +1,41 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type mockConfigValidator struct {
+	err error
+}
+
+func (m *mockConfigValidator) Validate() error {
+	return m.err
+}
+
+func TestCheckConfigTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "valid config"},
+		{name: "invalid config", err: errors.New("redis.url is required"), wantErr: appErrors.ErrInvalidConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewCheckConfigTask(&mockConfigValidator{err: tt.err}).Execute(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Execute() error = %v does not wrap %v", err, tt.err)
+			}
+		})
+	}
+}
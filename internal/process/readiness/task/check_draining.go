@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type DrainState interface {
+	Draining() bool
+}
+
+type CheckDrainingTask struct {
+	state DrainState
+}
+
+func NewCheckDrainingTask(s DrainState) *CheckDrainingTask {
+	return &CheckDrainingTask{
+		state: s,
+	}
+}
+
+func (t *CheckDrainingTask) Execute(ctx context.Context) error {
+	if t.state.Draining() {
+		return errors.ErrShuttingDown
+	}
+	return nil
+}
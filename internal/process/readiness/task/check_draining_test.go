@@ -0,0 +1,25 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/lifecycle"
+)
+
+func TestCheckDrainingTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	state := lifecycle.NewState()
+	task := NewCheckDrainingTask(state)
+
+	if err := task.Execute(ctx); err != nil {
+		t.Fatalf("unexpected error before drain: %v", err)
+	}
+
+	state.Drain()
+	if err := task.Execute(ctx); !errors.Is(err, appErrors.ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type PingRedisClient interface {
+	Ping(ctx context.Context) error
+}
+
+type PingRedisTask struct {
+	client  PingRedisClient
+	timeout time.Duration
+}
+
+func NewPingRedisTask(c PingRedisClient, timeout time.Duration) *PingRedisTask {
+	return &PingRedisTask{
+		client:  c,
+		timeout: timeout,
+	}
+}
+
+func (t *PingRedisTask) Execute(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	if err := t.client.Ping(ctx); err != nil {
+		return errors.ErrRedisUnavailable.Wrap(err)
+	}
+	return nil
+}
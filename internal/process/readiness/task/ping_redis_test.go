@@ -0,0 +1,54 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+type mockPingRedisClient struct {
+	pingFunc func(ctx context.Context) error
+}
+
+func (m *mockPingRedisClient) Ping(ctx context.Context) error {
+	return m.pingFunc(ctx)
+}
+
+func TestPingRedisTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		pingFunc func(ctx context.Context) error
+		wantErr  error
+	}{
+		{
+			name:     "redis answers",
+			pingFunc: func(ctx context.Context) error { return nil },
+		},
+		{
+			name:     "redis fails",
+			pingFunc: func(ctx context.Context) error { return errors.New("connection refused") },
+			wantErr:  appErrors.ErrRedisUnavailable,
+		},
+		{
+			name: "redis hangs",
+			pingFunc: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			wantErr: appErrors.ErrRedisUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := NewPingRedisTask(&mockPingRedisClient{pingFunc: tt.pingFunc}, 10*time.Millisecond)
+			if err := task.Execute(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
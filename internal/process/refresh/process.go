@@ -0,0 +1,108 @@
+package refresh
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	refreshTask "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/refresh/task"
+)
+
+type CheckRefreshableCaptchaTask interface {
+	Execute(ctx context.Context, id string) error
+}
+
+type InvalidateCaptchaTask interface {
+	Execute(ctx context.Context, id string) (*refreshTask.InvalidatedCaptcha, error)
+}
+
+type AssessRiskTask interface {
+	Execute(ctx context.Context, c client.Client) (risk.Assessment, error)
+}
+
+type GenerateCaptchaTask interface {
+	Execute(ctx context.Context) (*task.GeneratedCaptcha, error)
+}
+
+type SaveRefreshedCaptchaTask interface {
+	Execute(ctx context.Context, id, value string, old *refreshTask.InvalidatedCaptcha) error
+}
+
+type Request struct {
+	CaptchaId string        `json:"captchaId"`
+	Client    client.Client `json:"-"`
+}
+
+type Response struct {
+	CaptchaId   string `json:"captchaId"`
+	CaptchaImg  string `json:"captchaImg"`
+	CaptchaType string `json:"captchaType"`
+}
+
+type Process struct {
+	checkRefreshableTask  CheckRefreshableCaptchaTask
+	invalidateCaptchaTask InvalidateCaptchaTask
+	assessRiskTask        AssessRiskTask
+	generateCaptchaTask   GenerateCaptchaTask
+	hardCaptchaTask       GenerateCaptchaTask
+	saveCaptchaTask       SaveRefreshedCaptchaTask
+}
+
+func NewProcess(
+	checkRefreshableTask CheckRefreshableCaptchaTask,
+	invalidateCaptchaTask InvalidateCaptchaTask,
+	assessRiskTask AssessRiskTask,
+	generateCaptchaTask GenerateCaptchaTask,
+	hardCaptchaTask GenerateCaptchaTask,
+	saveCaptchaTask SaveRefreshedCaptchaTask,
+) *Process {
+	return &Process{
+		checkRefreshableTask:  checkRefreshableTask,
+		invalidateCaptchaTask: invalidateCaptchaTask,
+		assessRiskTask:        assessRiskTask,
+		generateCaptchaTask:   generateCaptchaTask,
+		hardCaptchaTask:       hardCaptchaTask,
+		saveCaptchaTask:       saveCaptchaTask,
+	}
+}
+
+func (p *Process) Process(ctx context.Context, req Request) (_ *Response, err error) {
+	ctx, span := tracing.Start(ctx, "refresh.Process")
+	defer func() { tracing.End(span, err) }()
+
+	if err := p.checkRefreshableTask.Execute(ctx, req.CaptchaId); err != nil {
+		return nil, err
+	}
+
+	assessment, err := p.assessRiskTask.Execute(ctx, req.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	generate := p.generateCaptchaTask
+	if assessment.Level.HardCaptcha {
+		generate = p.hardCaptchaTask
+	}
+
+	c, err := generate.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := p.invalidateCaptchaTask.Execute(ctx, req.CaptchaId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.saveCaptchaTask.Execute(ctx, c.Id, c.Answer, old); err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		CaptchaId:   c.Id,
+		CaptchaImg:  c.Data,
+		CaptchaType: c.Type,
+	}, nil
+}
@@ -0,0 +1,185 @@
+package refresh
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	refreshTask "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/refresh/task"
+)
+
+type mockCheckRefreshableCaptchaTask struct {
+	executeFunc func(ctx context.Context, id string) error
+}
+
+func (m *mockCheckRefreshableCaptchaTask) Execute(ctx context.Context, id string) error {
+	return m.executeFunc(ctx, id)
+}
+
+type mockInvalidateCaptchaTask struct {
+	executeFunc func(ctx context.Context, id string) (*refreshTask.InvalidatedCaptcha, error)
+}
+
+func (m *mockInvalidateCaptchaTask) Execute(ctx context.Context, id string) (*refreshTask.InvalidatedCaptcha, error) {
+	return m.executeFunc(ctx, id)
+}
+
+type mockAssessRiskTask struct {
+	executeFunc func(ctx context.Context, c client.Client) (risk.Assessment, error)
+}
+
+func (m *mockAssessRiskTask) Execute(ctx context.Context, c client.Client) (risk.Assessment, error) {
+	return m.executeFunc(ctx, c)
+}
+
+type mockGenerateCaptchaTask struct {
+	executeFunc func(ctx context.Context) (*task.GeneratedCaptcha, error)
+}
+
+func (m *mockGenerateCaptchaTask) Execute(ctx context.Context) (*task.GeneratedCaptcha, error) {
+	return m.executeFunc(ctx)
+}
+
+type mockSaveRefreshedCaptchaTask struct {
+	executeFunc func(ctx context.Context, id, value string, old *refreshTask.InvalidatedCaptcha) error
+}
+
+func (m *mockSaveRefreshedCaptchaTask) Execute(ctx context.Context, id, value string, old *refreshTask.InvalidatedCaptcha) error {
+	return m.executeFunc(ctx, id, value, old)
+}
+
+func TestProcess_Refresh(t *testing.T) {
+	generated := func(ctx context.Context) (*task.GeneratedCaptcha, error) {
+		return &task.GeneratedCaptcha{Id: "new-id", Data: "img", Answer: "ans", Type: task.DriverString}, nil
+	}
+	mustNotInvalidate := func(ctx context.Context, id string) (*refreshTask.InvalidatedCaptcha, error) {
+		t.Error("must not invalidate the old captcha before a new one is generated")
+		return &refreshTask.InvalidatedCaptcha{}, nil
+	}
+
+	tests := []struct {
+		name           string
+		checkErr       error
+		invalidateFunc func(context.Context, string) (*refreshTask.InvalidatedCaptcha, error)
+		assessment     risk.Assessment
+		riskErr        error
+		generateFunc   func(context.Context) (*task.GeneratedCaptcha, error)
+		hardFunc       func(context.Context) (*task.GeneratedCaptcha, error)
+		saveFunc       func(context.Context, string, string, *refreshTask.InvalidatedCaptcha) error
+		wantErr        error
+	}{
+		{
+			name: "successful refresh",
+			invalidateFunc: func(ctx context.Context, id string) (*refreshTask.InvalidatedCaptcha, error) {
+				return &refreshTask.InvalidatedCaptcha{Refreshes: 1, Site: "site-key"}, nil
+			},
+			generateFunc: generated,
+			saveFunc: func(ctx context.Context, id, value string, old *refreshTask.InvalidatedCaptcha) error {
+				if id != "new-id" || value != "ans" || old.Site != "site-key" || old.Refreshes != 1 {
+					t.Errorf("unexpected save: id=%s, value=%s, old=%+v", id, value, old)
+				}
+				return nil
+			},
+		},
+		{
+			name: "hard captcha for escalated client",
+			invalidateFunc: func(ctx context.Context, id string) (*refreshTask.InvalidatedCaptcha, error) {
+				return &refreshTask.InvalidatedCaptcha{}, nil
+			},
+			assessment: risk.Assessment{Score: 5, Level: risk.Level{Score: 5, HardCaptcha: true}},
+			generateFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) {
+				return nil, errors.New("normal generator used")
+			},
+			hardFunc: generated,
+			saveFunc: func(ctx context.Context, id, value string, old *refreshTask.InvalidatedCaptcha) error { return nil },
+		},
+		{
+			name:     "check error",
+			checkErr: errors.New("limit"),
+			generateFunc: func(ctx context.Context) (*task.GeneratedCaptcha, error) {
+				t.Error("must not generate a captcha when refresh is rejected")
+				return nil, nil
+			},
+			invalidateFunc: mustNotInvalidate,
+			wantErr:        errors.New("limit"),
+		},
+		{
+			name:           "risk assessment error",
+			invalidateFunc: mustNotInvalidate,
+			riskErr:        errors.New("risk fail"),
+			wantErr:        errors.New("risk fail"),
+		},
+		{
+			name: "invalidate error",
+			invalidateFunc: func(ctx context.Context, id string) (*refreshTask.InvalidatedCaptcha, error) {
+				return nil, errors.New("limit")
+			},
+			generateFunc: generated,
+			saveFunc: func(ctx context.Context, id, value string, old *refreshTask.InvalidatedCaptcha) error {
+				t.Error("must not save a captcha when refresh is rejected")
+				return nil
+			},
+			wantErr: errors.New("limit"),
+		},
+		{
+			name:           "generate error",
+			invalidateFunc: mustNotInvalidate,
+			generateFunc:   func(ctx context.Context) (*task.GeneratedCaptcha, error) { return nil, errors.New("gen fail") },
+			wantErr:        errors.New("gen fail"),
+		},
+		{
+			name: "save error",
+			invalidateFunc: func(ctx context.Context, id string) (*refreshTask.InvalidatedCaptcha, error) {
+				return &refreshTask.InvalidatedCaptcha{}, nil
+			},
+			generateFunc: generated,
+			saveFunc: func(ctx context.Context, id, value string, old *refreshTask.InvalidatedCaptcha) error {
+				return errors.New("save fail")
+			},
+			wantErr: errors.New("save fail"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProcess(
+				&mockCheckRefreshableCaptchaTask{executeFunc: func(ctx context.Context, id string) error {
+					if id != "old-id" {
+						t.Errorf("unexpected id: %s", id)
+					}
+					return tt.checkErr
+				}},
+				&mockInvalidateCaptchaTask{executeFunc: tt.invalidateFunc},
+				&mockAssessRiskTask{executeFunc: func(ctx context.Context, c client.Client) (risk.Assessment, error) {
+					if c.IP != "203.0.113.7" {
+						t.Errorf("client not passed: %+v", c)
+					}
+					return tt.assessment, tt.riskErr
+				}},
+				&mockGenerateCaptchaTask{executeFunc: tt.generateFunc},
+				&mockGenerateCaptchaTask{executeFunc: tt.hardFunc},
+				&mockSaveRefreshedCaptchaTask{executeFunc: tt.saveFunc},
+			)
+
+			resp, err := p.Process(context.Background(), Request{CaptchaId: "old-id", Client: client.Client{IP: "203.0.113.7"}})
+
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Errorf("Process() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+
+			if resp.CaptchaId != "new-id" || resp.CaptchaImg != "img" || resp.CaptchaType != task.DriverString {
+				t.Errorf("Process() unexpected response: %+v", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+const checkRefreshableCaptchaScript = `
+-- check_refreshable_captcha
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return 'not_found'
+end
+
+local captcha = cjson.decode(data)
+if captcha.solved then
+	return 'already_solved'
+end
+
+if (captcha.refreshes or 0) >= tonumber(ARGV[1]) then
+	return 'limit'
+end
+
+return 'ok'
+`
+
+type CheckRefreshableCaptchaRedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type CheckRefreshableCaptchaTask struct {
+	client       CheckRefreshableCaptchaRedisClient
+	maxRefreshes int
+}
+
+func NewCheckRefreshableCaptchaTask(c CheckRefreshableCaptchaRedisClient, maxRefreshes int) *CheckRefreshableCaptchaTask {
+	return &CheckRefreshableCaptchaTask{
+		client:       c,
+		maxRefreshes: maxRefreshes,
+	}
+}
+
+func (t *CheckRefreshableCaptchaTask) Execute(ctx context.Context, id string) (err error) {
+	ctx, span := tracing.Start(ctx, "refresh.CheckRefreshableCaptchaTask")
+	defer func() { tracing.End(span, err) }()
+
+	if id == "" {
+		return errors.ErrInvalidInput
+	}
+
+	key := fmt.Sprintf("captcha:%s", id)
+
+	res, err := t.client.Eval(ctx, checkRefreshableCaptchaScript, []string{key}, t.maxRefreshes)
+	if err != nil {
+		return errors.ErrInternalServerError.Wrap(err)
+	}
+
+	switch res {
+	case "ok":
+		return nil
+	case "limit":
+		return errors.ErrRefreshLimit
+	case "already_solved":
+		return errors.ErrCaptchaSolved
+	case "not_found":
+		return errors.ErrCaptchaNotFound
+	default:
+		return errors.ErrInternalServerError
+	}
+}
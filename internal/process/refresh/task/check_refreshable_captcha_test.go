@@ -0,0 +1,80 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task/captchatest"
+)
+
+func TestCheckRefreshableCaptchaTask_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		state   *taskCaptcha.Captcha
+		id      string
+		wantErr error
+	}{
+		{
+			name:  "refreshable",
+			state: &taskCaptcha.Captcha{Value: "ABC", TriesLeft: 3, Refreshes: 1},
+			id:    "id",
+		},
+		{
+			name:    "limit reached",
+			state:   &taskCaptcha.Captcha{Value: "ABC", TriesLeft: 3, Refreshes: 2},
+			id:      "id",
+			wantErr: appErrors.ErrRefreshLimit,
+		},
+		{
+			name:    "already solved",
+			state:   &taskCaptcha.Captcha{Value: "ABC", Solved: true},
+			id:      "id",
+			wantErr: appErrors.ErrCaptchaSolved,
+		},
+		{
+			name:    "not found",
+			id:      "id",
+			wantErr: appErrors.ErrCaptchaNotFound,
+		},
+		{
+			name:    "empty id",
+			id:      "",
+			wantErr: appErrors.ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, client := captchatest.NewRedis(t)
+			if tt.state != nil {
+				mr, client = captchatest.NewRedisCaptcha(t, *tt.state)
+			}
+			task := NewCheckRefreshableCaptchaTask(client, 2)
+
+			err := task.Execute(ctx, tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.state != nil && !mr.Exists(captchatest.Key) {
+				t.Error("checking must not touch the captcha")
+			}
+		})
+	}
+
+	t.Run("store error", func(t *testing.T) {
+		m := &mockInvalidateCaptchaRedisClient{
+			evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+				return nil, errors.New("redis fail")
+			},
+		}
+		task := NewCheckRefreshableCaptchaTask(m, 2)
+		if err := task.Execute(ctx, "id"); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+)
+
+// invalidateCaptchaScript deletes an unsolved captcha in one step and returns the
+// state its replacement inherits, unless the refresh limit in ARGV[1] is reached.
+const invalidateCaptchaScript = `
+-- invalidate_captcha
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return {'not_found'}
+end
+
+local captcha = cjson.decode(data)
+if captcha.solved then
+	return {'already_solved'}
+end
+
+local refreshes = captcha.refreshes or 0
+if refreshes >= tonumber(ARGV[1]) then
+	return {'limit'}
+end
+
+local ttl = redis.call('PTTL', KEYS[1])
+redis.call('DEL', KEYS[1])
+return {'ok', data, ttl}
+`
+
+type InvalidatedCaptcha struct {
+	Refreshes      int
+	Site           string
+	TriesLeft      int
+	FailedAttempts int
+	AudioValue     string
+	IssuedAt       int64
+	TTL            time.Duration
+}
+
+type InvalidateCaptchaRedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type InvalidateCaptchaTask struct {
+	client       InvalidateCaptchaRedisClient
+	maxRefreshes int
+}
+
+func NewInvalidateCaptchaTask(c InvalidateCaptchaRedisClient, maxRefreshes int) *InvalidateCaptchaTask {
+	return &InvalidateCaptchaTask{
+		client:       c,
+		maxRefreshes: maxRefreshes,
+	}
+}
+
+func (t *InvalidateCaptchaTask) Execute(ctx context.Context, id string) (_ *InvalidatedCaptcha, err error) {
+	ctx, span := tracing.Start(ctx, "refresh.InvalidateCaptchaTask")
+	defer func() { tracing.End(span, err) }()
+
+	if id == "" {
+		return nil, errors.ErrInvalidInput
+	}
+
+	key := fmt.Sprintf("captcha:%s", id)
+
+	res, err := t.client.Eval(ctx, invalidateCaptchaScript, []string{key}, t.maxRefreshes)
+	if err != nil {
+		return nil, errors.ErrInternalServerError.Wrap(err)
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, errors.ErrInternalServerError
+	}
+
+	switch values[0] {
+	case "ok":
+		if len(values) != 3 {
+			return nil, errors.ErrInternalServerError
+		}
+		data, ok := values[1].(string)
+		if !ok {
+			return nil, errors.ErrInternalServerError
+		}
+		ttl, ok := values[2].(int64)
+		if !ok {
+			return nil, errors.ErrInternalServerError
+		}
+		var c taskCaptcha.Captcha
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, errors.ErrInternalServerError.Wrap(err)
+		}
+		return &InvalidatedCaptcha{
+			Refreshes:      c.Refreshes,
+			Site:           c.Site,
+			TriesLeft:      c.TriesLeft,
+			FailedAttempts: c.FailedAttempts,
+			AudioValue:     c.AudioValue,
+			IssuedAt:       c.IssuedAt,
+			TTL:            time.Duration(ttl) * time.Millisecond,
+		}, nil
+	case "limit":
+		return nil, errors.ErrRefreshLimit
+	case "already_solved":
+		return nil, errors.ErrCaptchaSolved
+	case "not_found":
+		return nil, errors.ErrCaptchaNotFound
+	default:
+		return nil, errors.ErrInternalServerError
+	}
+}
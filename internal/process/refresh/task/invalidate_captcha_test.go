@@ -0,0 +1,96 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task/captchatest"
+)
+
+type mockInvalidateCaptchaRedisClient struct {
+	evalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+func (m *mockInvalidateCaptchaRedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return m.evalFunc(ctx, script, keys, args...)
+}
+
+func TestInvalidateCaptchaTask_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("first refresh", func(t *testing.T) {
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "ABC", TriesLeft: 2, FailedAttempts: 1, AudioValue: "1234", IssuedAt: 1700000000, Site: "site-key"})
+		mr.SetTTL("captcha:id", time.Minute)
+		task := NewInvalidateCaptchaTask(client, 2)
+		old, err := task.Execute(ctx, "id")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if old.Refreshes != 0 || old.Site != "site-key" || old.TriesLeft != 2 || old.TTL != time.Minute ||
+			old.FailedAttempts != 1 || old.AudioValue != "1234" || old.IssuedAt != 1700000000 {
+			t.Errorf("unexpected invalidated captcha: %+v", old)
+		}
+		if mr.Exists("captcha:id") {
+			t.Error("expected old captcha to be deleted")
+		}
+	})
+
+	t.Run("refresh within limit", func(t *testing.T) {
+		_, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "ABC", TriesLeft: 3, Refreshes: 1})
+		task := NewInvalidateCaptchaTask(client, 2)
+		old, err := task.Execute(ctx, "id")
+		if err != nil || old.Refreshes != 1 {
+			t.Errorf("expected 1 refresh, got %+v, %v", old, err)
+		}
+	})
+
+	t.Run("limit reached", func(t *testing.T) {
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "ABC", TriesLeft: 3, Refreshes: 2})
+		task := NewInvalidateCaptchaTask(client, 2)
+		if _, err := task.Execute(ctx, "id"); !errors.Is(err, appErrors.ErrRefreshLimit) {
+			t.Errorf("expected ErrRefreshLimit, got %v", err)
+		}
+		if !mr.Exists("captcha:id") {
+			t.Error("captcha must stay usable when the limit is reached")
+		}
+	})
+
+	t.Run("already solved", func(t *testing.T) {
+		_, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "ABC", Solved: true})
+		task := NewInvalidateCaptchaTask(client, 2)
+		if _, err := task.Execute(ctx, "id"); !errors.Is(err, appErrors.ErrCaptchaSolved) {
+			t.Errorf("expected ErrCaptchaSolved, got %v", err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, client := captchatest.NewRedis(t)
+		task := NewInvalidateCaptchaTask(client, 2)
+		if _, err := task.Execute(ctx, "id"); !errors.Is(err, appErrors.ErrCaptchaNotFound) {
+			t.Errorf("expected ErrCaptchaNotFound, got %v", err)
+		}
+	})
+
+	t.Run("empty id", func(t *testing.T) {
+		task := NewInvalidateCaptchaTask(&mockInvalidateCaptchaRedisClient{}, 2)
+		if _, err := task.Execute(ctx, ""); !errors.Is(err, appErrors.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		m := &mockInvalidateCaptchaRedisClient{
+			evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+				return nil, errors.New("redis fail")
+			},
+		}
+		task := NewInvalidateCaptchaTask(m, 2)
+		if _, err := task.Execute(ctx, "id"); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+}
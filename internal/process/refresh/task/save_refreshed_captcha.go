@@ -0,0 +1,58 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+)
+
+type SaveRefreshedCaptchaRedisClient interface {
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
+
+type SaveRefreshedCaptchaTask struct {
+	client SaveRefreshedCaptchaRedisClient
+}
+
+func NewSaveRefreshedCaptchaTask(c SaveRefreshedCaptchaRedisClient) *SaveRefreshedCaptchaTask {
+	return &SaveRefreshedCaptchaTask{
+		client: c,
+	}
+}
+
+func (t *SaveRefreshedCaptchaTask) Execute(ctx context.Context, id, value string, old *InvalidatedCaptcha) (err error) {
+	ctx, span := tracing.Start(ctx, "refresh.SaveRefreshedCaptchaTask")
+	defer func() { tracing.End(span, err) }()
+
+	captcha := taskCaptcha.Captcha{
+		Value:          value,
+		AudioValue:     old.AudioValue,
+		TriesLeft:      old.TriesLeft,
+		FailedAttempts: old.FailedAttempts,
+		IssuedAt:       old.IssuedAt,
+		Refreshes:      old.Refreshes + 1,
+		Site:           old.Site,
+	}
+
+	data, err := json.Marshal(captcha)
+	if err != nil {
+		return errors.ErrInternalServerError.Wrap(err)
+	}
+
+	key := fmt.Sprintf("captcha:%s", id)
+
+	ttl := old.TTL
+	if ttl < 0 {
+		ttl = 0
+	}
+	if err := t.client.Set(ctx, key, string(data), ttl); err != nil {
+		return errors.ErrInternalServerError.Wrap(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+)
+
+type mockSaveRefreshedCaptchaRedisClient struct {
+	setFunc func(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
+
+func (m *mockSaveRefreshedCaptchaRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return m.setFunc(ctx, key, value, expiration)
+}
+
+func TestSaveRefreshedCaptchaTask_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("carries the state of the old captcha", func(t *testing.T) {
+		var savedKey string
+		var saved taskCaptcha.Captcha
+		var savedTtl time.Duration
+		m := &mockSaveRefreshedCaptchaRedisClient{
+			setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+				savedKey, savedTtl = key, expiration
+				return json.Unmarshal([]byte(value.(string)), &saved)
+			},
+		}
+		task := NewSaveRefreshedCaptchaTask(m)
+		old := &InvalidatedCaptcha{Refreshes: 1, Site: "site-key", TriesLeft: 2, FailedAttempts: 1, AudioValue: "1234", IssuedAt: 1700000000, TTL: 90 * time.Second}
+		if err := task.Execute(ctx, "new-id", "answer", old); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if savedKey != "captcha:new-id" || savedTtl != 90*time.Second {
+			t.Errorf("key = %q, ttl = %v", savedKey, savedTtl)
+		}
+		if saved.Value != "answer" || saved.TriesLeft != 2 || saved.Refreshes != 2 || saved.Site != "site-key" ||
+			saved.FailedAttempts != 1 || saved.AudioValue != "1234" || saved.IssuedAt != 1700000000 {
+			t.Errorf("unexpected record: %+v", saved)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		m := &mockSaveRefreshedCaptchaRedisClient{
+			setFunc: func(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+				return errors.New("fail")
+			},
+		}
+		task := NewSaveRefreshedCaptchaTask(m)
+		if err := task.Execute(ctx, "new-id", "answer", &InvalidatedCaptcha{TTL: time.Minute}); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package task
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+const readRiskScript = `
+-- read_risk
+local data = redis.call('HMGET', KEYS[1], 'score', 'at')
+if not data[1] then
+	return {'0', '0'}
+end
+return {data[1], data[2]}
+`
+
+type AssessRiskRedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type AssessRiskTask struct {
+	client AssessRiskRedisClient
+	policy risk.Policy
+}
+
+func NewAssessRiskTask(c AssessRiskRedisClient, policy risk.Policy) *AssessRiskTask {
+	return &AssessRiskTask{
+		client: c,
+		policy: policy,
+	}
+}
+
+func (t *AssessRiskTask) Execute(ctx context.Context, c client.Client) (_ risk.Assessment, err error) {
+	ctx, span := tracing.Start(ctx, "risk.AssessRiskTask")
+	defer func() { tracing.End(span, err) }()
+
+	if !t.policy.Enabled() {
+		return risk.Assessment{}, nil
+	}
+
+	res, err := t.client.Eval(ctx, readRiskScript, []string{"risk:" + t.policy.Key(c.IP)})
+	if err != nil {
+		return risk.Assessment{}, errors.ErrInternalServerError.Wrap(err)
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 2 {
+		return risk.Assessment{}, errors.ErrInternalServerError
+	}
+	scoreStr, _ := values[0].(string)
+	atStr, _ := values[1].(string)
+
+	score, err := strconv.ParseFloat(scoreStr, 64)
+	if err != nil {
+		return risk.Assessment{}, errors.ErrInternalServerError.Wrap(err)
+	}
+	at, err := strconv.ParseInt(atStr, 10, 64)
+	if err != nil {
+		return risk.Assessment{}, errors.ErrInternalServerError.Wrap(err)
+	}
+
+	score = t.policy.Decay(score, time.Since(time.UnixMilli(at)))
+
+	return risk.Assessment{Score: score, Level: t.policy.LevelFor(score)}, nil
+}
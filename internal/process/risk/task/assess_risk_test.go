@@ -0,0 +1,86 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+)
+
+func TestAssessRiskTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		score     string
+		at        time.Time
+		wantScore bool
+		wantExtra int
+		wantHard  bool
+	}{
+		{name: "unknown client"},
+		{name: "below first level", score: "1.5", at: now, wantScore: true},
+		{name: "first level", score: "2.5", at: now, wantScore: true, wantExtra: 2},
+		{name: "top level", score: "6", at: now, wantScore: true, wantExtra: 4, wantHard: true},
+		{name: "decayed to first level", score: "6", at: now.Add(-10 * time.Minute), wantScore: true, wantExtra: 2},
+		{name: "decayed below first level", score: "6", at: now.Add(-time.Hour), wantScore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, c := newRedis(t)
+			if tt.score != "" {
+				mr.HSet("risk:203.0.113.0/24", "score", tt.score, "at", strconv.FormatInt(tt.at.UnixMilli(), 10))
+			}
+
+			assessment, err := NewAssessRiskTask(c, newPolicy(t)).Execute(ctx, client.Client{IP: "203.0.113.99"})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (assessment.Score > 0) != tt.wantScore {
+				t.Errorf("unexpected score: %v", assessment.Score)
+			}
+			if assessment.Level.ExtraDifficulty != tt.wantExtra || assessment.Level.HardCaptcha != tt.wantHard {
+				t.Errorf("unexpected level: %+v", assessment.Level)
+			}
+		})
+	}
+
+	t.Run("record then assess", func(t *testing.T) {
+		_, c := newRedis(t)
+		record := NewRecordRiskTask(c, newPolicy(t))
+		assess := NewAssessRiskTask(c, newPolicy(t))
+		for i := 0; i < 5; i++ {
+			record.Execute(ctx, client.Client{IP: "203.0.113.7"})
+		}
+		assessment, err := assess.Execute(ctx, client.Client{IP: "203.0.113.7"})
+		if err != nil || !assessment.Level.HardCaptcha {
+			t.Errorf("expected top level, got %+v, %v", assessment, err)
+		}
+	})
+
+	t.Run("disabled policy", func(t *testing.T) {
+		c := &mockRedisClient{evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+			t.Error("redis should not be called")
+			return nil, nil
+		}}
+		if assessment, err := NewAssessRiskTask(c, risk.Policy{}).Execute(ctx, client.Client{IP: "203.0.113.7"}); err != nil || assessment != (risk.Assessment{}) {
+			t.Errorf("unexpected result: %+v, %v", assessment, err)
+		}
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		c := &mockRedisClient{evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+			return nil, errors.New("down")
+		}}
+		if _, err := NewAssessRiskTask(c, newPolicy(t)).Execute(ctx, client.Client{IP: "203.0.113.7"}); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+// recordRiskScript decays the stored score and adds one point in one step.
+const recordRiskScript = `
+-- record_risk
+local data = redis.call('HMGET', KEYS[1], 'score', 'at')
+local now = tonumber(ARGV[1])
+local score = tonumber(data[1]) or 0
+local at = tonumber(data[2]) or now
+if now > at then
+	score = score * 0.5 ^ ((now - at) / tonumber(ARGV[2]))
+end
+score = score + 1
+redis.call('HSET', KEYS[1], 'score', tostring(score), 'at', ARGV[1])
+redis.call('PEXPIRE', KEYS[1], tonumber(ARGV[2]) * 10)
+return tostring(score)
+`
+
+type RecordRiskRedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type RecordRiskTask struct {
+	client RecordRiskRedisClient
+	policy risk.Policy
+}
+
+func NewRecordRiskTask(c RecordRiskRedisClient, policy risk.Policy) *RecordRiskTask {
+	return &RecordRiskTask{
+		client: c,
+		policy: policy,
+	}
+}
+
+func (t *RecordRiskTask) Execute(ctx context.Context, c client.Client) (err error) {
+	ctx, span := tracing.Start(ctx, "risk.RecordRiskTask")
+	defer func() { tracing.End(span, err) }()
+
+	if !t.policy.Enabled() {
+		return nil
+	}
+
+	keys := []string{"risk:" + t.policy.Key(c.IP)}
+	if _, err := t.client.Eval(ctx, recordRiskScript, keys, time.Now().UnixMilli(), t.policy.HalfLife.Milliseconds()); err != nil {
+		return errors.ErrInternalServerError.Wrap(err)
+	}
+
+	return nil
+}
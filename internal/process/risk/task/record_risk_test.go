@@ -0,0 +1,104 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/risk"
+	serviceRedis "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+type mockRedisClient struct {
+	evalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+func (m *mockRedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return m.evalFunc(ctx, script, keys, args...)
+}
+
+func newRedis(t *testing.T) (*miniredis.Miniredis, serviceRedis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	c, err := serviceRedis.NewClient("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return mr, c
+}
+
+func newPolicy(t *testing.T) risk.Policy {
+	t.Helper()
+	p, err := risk.NewPolicy(10*time.Minute, 24, 64, []risk.Level{
+		{Score: 2, ExtraDifficulty: 2},
+		{Score: 4, ExtraDifficulty: 4, HardCaptcha: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return p
+}
+
+func TestRecordRiskTask_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("accumulates per subnet", func(t *testing.T) {
+		mr, c := newRedis(t)
+		task := NewRecordRiskTask(c, newPolicy(t))
+
+		for _, ip := range []string{"203.0.113.7", "203.0.113.8"} {
+			if err := task.Execute(ctx, client.Client{IP: ip}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+
+		score, _ := strconv.ParseFloat(mr.HGet("risk:203.0.113.0/24", "score"), 64)
+		if score < 1.99 || score > 2 {
+			t.Errorf("unexpected score: %v", score)
+		}
+		if ttl := mr.TTL("risk:203.0.113.0/24"); ttl != 100*time.Minute {
+			t.Errorf("unexpected ttl: %v", ttl)
+		}
+	})
+
+	t.Run("decays previous score", func(t *testing.T) {
+		mr, c := newRedis(t)
+		task := NewRecordRiskTask(c, newPolicy(t))
+
+		at := time.Now().Add(-20 * time.Minute).UnixMilli()
+		mr.HSet("risk:203.0.113.0/24", "score", "8", "at", strconv.FormatInt(at, 10))
+
+		if err := task.Execute(ctx, client.Client{IP: "203.0.113.7"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		score, _ := strconv.ParseFloat(mr.HGet("risk:203.0.113.0/24", "score"), 64)
+		if score < 2.99 || score > 3.01 {
+			t.Errorf("expected 8 decayed by two half-lives plus one, got %v", score)
+		}
+	})
+
+	t.Run("disabled policy", func(t *testing.T) {
+		c := &mockRedisClient{evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+			t.Error("redis should not be called")
+			return nil, nil
+		}}
+		if err := NewRecordRiskTask(c, risk.Policy{}).Execute(ctx, client.Client{IP: "203.0.113.7"}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("redis error", func(t *testing.T) {
+		c := &mockRedisClient{evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+			return nil, errors.New("down")
+		}}
+		if err := NewRecordRiskTask(c, newPolicy(t)).Execute(ctx, client.Client{IP: "203.0.113.7"}); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+}
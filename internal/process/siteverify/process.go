@@ -0,0 +1,73 @@
+package siteverify
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	captcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+)
+
+type AuthenticateServiceTask interface {
+	Execute(ctx context.Context, secret string) (string, error)
+}
+
+type RedeemCaptchaTask interface {
+	Execute(ctx context.Context, id, site string) (*captcha.Captcha, error)
+}
+
+type Request struct {
+	CaptchaId string `json:"captchaId"`
+	Secret    string `json:"secret"`
+}
+
+type Response struct {
+	CaptchaId      string `json:"captchaId"`
+	Success        bool   `json:"success"`
+	IssuedAt       int64  `json:"issuedAt"`
+	SolvedAt       int64  `json:"solvedAt"`
+	FailedAttempts int    `json:"failedAttempts"`
+	Mode           string `json:"mode"`
+	Site           string `json:"site"`
+}
+
+type Process struct {
+	authenticateServiceTask AuthenticateServiceTask
+	redeemCaptchaTask       RedeemCaptchaTask
+}
+
+func NewProcess(authenticateServiceTask AuthenticateServiceTask, redeemCaptchaTask RedeemCaptchaTask) *Process {
+	return &Process{
+		authenticateServiceTask: authenticateServiceTask,
+		redeemCaptchaTask:       redeemCaptchaTask,
+	}
+}
+
+func (p *Process) Process(ctx context.Context, req Request) (_ *Response, err error) {
+	ctx, span := tracing.Start(ctx, "siteverify.Process")
+	defer func() { tracing.End(span, err) }()
+
+	site, err := p.authenticateServiceTask.Execute(ctx, req.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := p.redeemCaptchaTask.Execute(ctx, req.CaptchaId, site)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := c.Mode
+	if mode == "" {
+		mode = captcha.ModeChallenge
+	}
+
+	return &Response{
+		CaptchaId:      req.CaptchaId,
+		Success:        true,
+		IssuedAt:       c.IssuedAt,
+		SolvedAt:       c.SolvedAt,
+		FailedAttempts: c.FailedAttempts,
+		Mode:           mode,
+		Site:           c.Site,
+	}, nil
+}
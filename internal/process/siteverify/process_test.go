@@ -0,0 +1,98 @@
+package siteverify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	captcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+)
+
+type mockAuthenticateServiceTask struct {
+	executeFunc func(ctx context.Context, secret string) (string, error)
+}
+
+func (m *mockAuthenticateServiceTask) Execute(ctx context.Context, secret string) (string, error) {
+	return m.executeFunc(ctx, secret)
+}
+
+type mockRedeemCaptchaTask struct {
+	executeFunc func(ctx context.Context, id, site string) (*captcha.Captcha, error)
+}
+
+func (m *mockRedeemCaptchaTask) Execute(ctx context.Context, id, site string) (*captcha.Captcha, error) {
+	return m.executeFunc(ctx, id, site)
+}
+
+func TestProcess_Siteverify(t *testing.T) {
+	tests := []struct {
+		name       string
+		authFunc   func(context.Context, string) (string, error)
+		redeemFunc func(context.Context, string, string) (*captcha.Captcha, error)
+		wantErr    error
+		wantResp   *Response
+	}{
+		{
+			name:     "successful redemption",
+			authFunc: func(ctx context.Context, s string) (string, error) { return "site-key", nil },
+			redeemFunc: func(ctx context.Context, id, site string) (*captcha.Captcha, error) {
+				if site != "site-key" {
+					t.Errorf("site of the service not passed: %q", site)
+				}
+				return &captcha.Captcha{Solved: true, IssuedAt: 10, SolvedAt: 25, FailedAttempts: 2, Site: "site-key"}, nil
+			},
+			wantResp: &Response{CaptchaId: "test-id", Success: true, IssuedAt: 10, SolvedAt: 25, FailedAttempts: 2, Mode: captcha.ModeChallenge, Site: "site-key"},
+		},
+		{
+			name:     "invisible redemption",
+			authFunc: func(ctx context.Context, s string) (string, error) { return "site-key", nil },
+			redeemFunc: func(ctx context.Context, id, site string) (*captcha.Captcha, error) {
+				return &captcha.Captcha{Solved: true, IssuedAt: 10, SolvedAt: 10, Mode: captcha.ModeInvisible, Site: "site-key"}, nil
+			},
+			wantResp: &Response{CaptchaId: "test-id", Success: true, IssuedAt: 10, SolvedAt: 10, Mode: captcha.ModeInvisible, Site: "site-key"},
+		},
+		{
+			name:     "unauthorized service",
+			authFunc: func(ctx context.Context, s string) (string, error) { return "", errors.New("unauthorized") },
+			redeemFunc: func(ctx context.Context, id, site string) (*captcha.Captcha, error) {
+				t.Error("redeem must not run for unauthorized services")
+				return nil, nil
+			},
+			wantErr: errors.New("unauthorized"),
+		},
+		{
+			name:     "redeem error",
+			authFunc: func(ctx context.Context, s string) (string, error) { return "site-key", nil },
+			redeemFunc: func(ctx context.Context, id, site string) (*captcha.Captcha, error) {
+				return nil, errors.New("not solved")
+			},
+			wantErr: errors.New("not solved"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProcess(
+				&mockAuthenticateServiceTask{executeFunc: tt.authFunc},
+				&mockRedeemCaptchaTask{executeFunc: tt.redeemFunc},
+			)
+
+			resp, err := p.Process(context.Background(), Request{CaptchaId: "test-id", Secret: "secret"})
+
+			if tt.wantErr != nil {
+				if err == nil || err.Error() != tt.wantErr.Error() {
+					t.Errorf("Process() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Process() unexpected error: %v", err)
+			}
+
+			if *resp != *tt.wantResp {
+				t.Errorf("Process() = %+v, want %+v", resp, tt.wantResp)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package task
+
+import (
+	"context"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/secure"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+)
+
+type AuthenticateServiceTask struct {
+	secrets map[string]string
+}
+
+func NewAuthenticateServiceTask(secrets map[string]string) *AuthenticateServiceTask {
+	return &AuthenticateServiceTask{
+		secrets: secrets,
+	}
+}
+
+func (t *AuthenticateServiceTask) Execute(ctx context.Context, secret string) (_ string, err error) {
+	_, span := tracing.Start(ctx, "siteverify.AuthenticateServiceTask")
+	defer func() { tracing.End(span, err) }()
+
+	if secret == "" {
+		return "", errors.ErrUnauthorizedService
+	}
+
+	for site, s := range t.secrets {
+		if s != "" && secure.Equal(s, secret) {
+			return site, nil
+		}
+	}
+
+	return "", errors.ErrUnauthorizedService
+}
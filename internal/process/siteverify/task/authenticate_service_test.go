@@ -0,0 +1,38 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+)
+
+func TestAuthenticateServiceTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	task := NewAuthenticateServiceTask(map[string]string{"contact": "contact-secret", "empty": "", "blog": "blog-secret"})
+
+	t.Run("known secret", func(t *testing.T) {
+		site, err := task.Execute(ctx, "blog-secret")
+		if err != nil || site != "blog" {
+			t.Errorf("got %q, %v, want blog", site, err)
+		}
+	})
+
+	t.Run("unknown secret", func(t *testing.T) {
+		if _, err := task.Execute(ctx, "other-secret"); err != errors.ErrUnauthorizedService {
+			t.Errorf("expected ErrUnauthorizedService, got %v", err)
+		}
+	})
+
+	t.Run("prefix of a known secret", func(t *testing.T) {
+		if _, err := task.Execute(ctx, "blog-secre"); err != errors.ErrUnauthorizedService {
+			t.Errorf("expected ErrUnauthorizedService, got %v", err)
+		}
+	})
+
+	t.Run("empty secret", func(t *testing.T) {
+		if _, err := task.Execute(ctx, ""); err != errors.ErrUnauthorizedService {
+			t.Errorf("expected ErrUnauthorizedService, got %v", err)
+		}
+	})
+}
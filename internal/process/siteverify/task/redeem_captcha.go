@@ -0,0 +1,110 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	captcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+)
+
+// redeemCaptchaScript deletes a solved captcha and returns it in one step, so
+// it can be redeemed at most once. Captchas it refuses are left untouched.
+const redeemCaptchaScript = `
+-- redeem_captcha
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return {'not_found'}
+end
+
+local captcha = cjson.decode(data)
+if not captcha.solved then
+	return {'not_solved'}
+end
+
+if (captcha.site or '') ~= ARGV[1] then
+	return {'site_mismatch'}
+end
+
+if captcha.mode == 'invisible' and ARGV[2] ~= '1' then
+	return {'mode_not_allowed'}
+end
+
+redis.call('DEL', KEYS[1])
+return {'ok', data}
+`
+
+type RedeemCaptchaRedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type RedeemCaptchaTask struct {
+	client         RedeemCaptchaRedisClient
+	invisibleSites map[string]bool
+}
+
+func NewRedeemCaptchaTask(c RedeemCaptchaRedisClient, invisibleSites []string) *RedeemCaptchaTask {
+	sites := make(map[string]bool, len(invisibleSites))
+	for _, site := range invisibleSites {
+		sites[site] = true
+	}
+
+	return &RedeemCaptchaTask{
+		client:         c,
+		invisibleSites: sites,
+	}
+}
+
+func (t *RedeemCaptchaTask) Execute(ctx context.Context, id, site string) (_ *captcha.Captcha, err error) {
+	ctx, span := tracing.Start(ctx, "siteverify.RedeemCaptchaTask")
+	defer func() { tracing.End(span, err) }()
+
+	if id == "" {
+		return nil, errors.ErrInvalidInput
+	}
+
+	key := fmt.Sprintf("captcha:%s", id)
+
+	invisible := "0"
+	if t.invisibleSites[site] {
+		invisible = "1"
+	}
+
+	res, err := t.client.Eval(ctx, redeemCaptchaScript, []string{key}, site, invisible)
+	if err != nil {
+		return nil, errors.ErrInternalServerError.Wrap(err)
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, errors.ErrInternalServerError
+	}
+
+	switch values[0] {
+	case "ok":
+		if len(values) != 2 {
+			return nil, errors.ErrInternalServerError
+		}
+		data, ok := values[1].(string)
+		if !ok {
+			return nil, errors.ErrInternalServerError
+		}
+		var c captcha.Captcha
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return nil, errors.ErrInternalServerError.Wrap(err)
+		}
+		return &c, nil
+	case "not_solved":
+		return nil, errors.ErrCaptchaNotSolved
+	case "site_mismatch":
+		return nil, errors.ErrCaptchaSite
+	case "mode_not_allowed":
+		return nil, errors.ErrCaptchaMode
+	case "not_found":
+		return nil, errors.ErrCaptchaNotFound
+	default:
+		return nil, errors.ErrInternalServerError
+	}
+}
@@ -0,0 +1,122 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task/captchatest"
+)
+
+type mockRedeemCaptchaRedisClient struct {
+	evalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+func (m *mockRedeemCaptchaRedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return m.evalFunc(ctx, script, keys, args...)
+}
+
+func TestRedeemCaptchaTask_Execute(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("solved", func(t *testing.T) {
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", Solved: true, FailedAttempts: 1, IssuedAt: 100, SolvedAt: 130, Site: "site-key"})
+
+		task := NewRedeemCaptchaTask(client, []string{"invisible-site"})
+		res, err := task.Execute(ctx, "id", "site-key")
+		if err != nil || res.SolvedAt != 130 || res.FailedAttempts != 1 {
+			t.Errorf("unexpected: err=%v, res=%+v", err, res)
+		}
+		if mr.Exists("captcha:id") {
+			t.Error("expected redeemed captcha to be deleted")
+		}
+		if _, err := task.Execute(ctx, "id", "site-key"); !errors.Is(err, appErrors.ErrCaptchaNotFound) {
+			t.Errorf("expected ErrCaptchaNotFound on second redemption, got %v", err)
+		}
+	})
+
+	t.Run("not solved", func(t *testing.T) {
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", TriesLeft: 3})
+		data, _ := mr.Get("captcha:id")
+
+		task := NewRedeemCaptchaTask(client, []string{"invisible-site"})
+		if _, err := task.Execute(ctx, "id", "site-key"); !errors.Is(err, appErrors.ErrCaptchaNotSolved) {
+			t.Errorf("expected ErrCaptchaNotSolved, got %v", err)
+		}
+		if stored, _ := mr.Get("captcha:id"); stored != data {
+			t.Errorf("unsolved captcha was modified: %s", stored)
+		}
+	})
+
+	t.Run("invisible solve for an invisible site", func(t *testing.T) {
+		_, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Solved: true, Mode: taskCaptcha.ModeInvisible, Site: "invisible-site"})
+		task := NewRedeemCaptchaTask(client, []string{"invisible-site"})
+		if _, err := task.Execute(ctx, "id", "invisible-site"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	refused := []struct {
+		name    string
+		state   taskCaptcha.Captcha
+		wantErr error
+	}{
+		{
+			name:    "solved for another site",
+			state:   taskCaptcha.Captcha{Solved: true, Site: "other-site"},
+			wantErr: appErrors.ErrCaptchaSite,
+		},
+		{
+			name:    "solved without a site",
+			state:   taskCaptcha.Captcha{Solved: true},
+			wantErr: appErrors.ErrCaptchaSite,
+		},
+		{
+			name:    "invisible solve for a challenge site",
+			state:   taskCaptcha.Captcha{Solved: true, Mode: taskCaptcha.ModeInvisible, Site: "site-key"},
+			wantErr: appErrors.ErrCaptchaMode,
+		},
+	}
+	for _, tt := range refused {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, client := captchatest.NewRedisCaptcha(t, tt.state)
+			data, _ := mr.Get("captcha:id")
+			task := NewRedeemCaptchaTask(client, []string{"invisible-site"})
+			if _, err := task.Execute(ctx, "id", "site-key"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+			if stored, _ := mr.Get("captcha:id"); stored != data {
+				t.Errorf("refused captcha was modified: %s", stored)
+			}
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		_, client := captchatest.NewRedis(t)
+		task := NewRedeemCaptchaTask(client, []string{"invisible-site"})
+		if _, err := task.Execute(ctx, "id", "site-key"); !errors.Is(err, appErrors.ErrCaptchaNotFound) {
+			t.Errorf("expected ErrCaptchaNotFound, got %v", err)
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		m := &mockRedeemCaptchaRedisClient{
+			evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+				return nil, errors.New("redis fail")
+			},
+		}
+		task := NewRedeemCaptchaTask(m, nil)
+		if _, err := task.Execute(ctx, "id", "site-key"); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+
+	t.Run("empty id", func(t *testing.T) {
+		task := NewRedeemCaptchaTask(&mockRedeemCaptchaRedisClient{}, nil)
+		if _, err := task.Execute(ctx, "", "site-key"); !errors.Is(err, appErrors.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput, got %v", err)
+		}
+	})
+}
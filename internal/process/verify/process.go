@@ -2,50 +2,71 @@ package verify_captcha
 
 import (
 	"context"
+	"errors"
 
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
 	captcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
 )
 
-type ReadCaptchaTask interface {
-	Execute(ctx context.Context, id string) (*captcha.Captcha, error)
+type ValidateCaptchaTask interface {
+	Execute(ctx context.Context, id, val string) (string, error)
 }
 
-type ValidateCaptchaTask interface {
-	Execute(ctx context.Context, id, val string, captcha *captcha.Captcha) error
+type RecordRiskTask interface {
+	Execute(ctx context.Context, c client.Client) error
+}
+
+type IssueTokenTask interface {
+	Execute(ctx context.Context, id, site, mode string) (string, error)
 }
 
 type Request struct {
-	CaptchaId    string `json:"captchaId"`
-	CaptchaValue string `json:"captchaValue"`
+	CaptchaId    string        `json:"captchaId"`
+	CaptchaValue string        `json:"captchaValue"`
+	Client       client.Client `json:"-"`
 }
 
 type Response struct {
 	CaptchaId string `json:"captchaId"`
+	Token     string `json:"token"`
 }
 
 type Process struct {
-	readCaptchaTask     ReadCaptchaTask
 	validateCaptchaTask ValidateCaptchaTask
+	recordRiskTask      RecordRiskTask
+	issueTokenTask      IssueTokenTask
 }
 
-func NewProcess(readCaptchaTask ReadCaptchaTask, validateCaptchaTask ValidateCaptchaTask) *Process {
+func NewProcess(validateCaptchaTask ValidateCaptchaTask, recordRiskTask RecordRiskTask, issueTokenTask IssueTokenTask) *Process {
 	return &Process{
-		readCaptchaTask:     readCaptchaTask,
 		validateCaptchaTask: validateCaptchaTask,
+		recordRiskTask:      recordRiskTask,
+		issueTokenTask:      issueTokenTask,
 	}
 }
 
-func (p *Process) Process(ctx context.Context, req Request) (*Response, error) {
-	c, err := p.readCaptchaTask.Execute(ctx, req.CaptchaId)
+func (p *Process) Process(ctx context.Context, req Request) (_ *Response, err error) {
+	ctx, span := tracing.Start(ctx, "verify.Process")
+	defer func() { tracing.End(span, err) }()
+
+	site, err := p.validateCaptchaTask.Execute(ctx, req.CaptchaId, req.CaptchaValue)
 	if err != nil {
+		// A failed risk update must not mask the verification result.
+		if errors.Is(err, appErrors.ErrInvalidCaptchaValue) || errors.Is(err, appErrors.ErrNoTriesLeft) {
+			_ = p.recordRiskTask.Execute(ctx, req.Client)
+		}
 		return nil, err
 	}
 
-	if err := p.validateCaptchaTask.Execute(ctx, req.CaptchaId, req.CaptchaValue, c); err != nil {
+	token, err := p.issueTokenTask.Execute(ctx, req.CaptchaId, site, captcha.ModeChallenge)
+	if err != nil {
 		return nil, err
 	}
 
 	return &Response{
 		CaptchaId: req.CaptchaId,
+		Token:     token,
 	}, nil
 }
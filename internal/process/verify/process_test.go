@@ -5,76 +5,121 @@ import (
 	"errors"
 	"testing"
 
-	captcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/client"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
 )
 
-type mockReadCaptchaTask struct {
-	executeFunc func(ctx context.Context, id string) (*captcha.Captcha, error)
+type mockValidateCaptchaTask struct {
+	executeFunc func(ctx context.Context, id, val string) (string, error)
 }
 
-func (m *mockReadCaptchaTask) Execute(ctx context.Context, id string) (*captcha.Captcha, error) {
-	return m.executeFunc(ctx, id)
+func (m *mockValidateCaptchaTask) Execute(ctx context.Context, id, val string) (string, error) {
+	return m.executeFunc(ctx, id, val)
 }
 
-type mockValidateCaptchaTask struct {
-	executeFunc func(ctx context.Context, id, val string, c *captcha.Captcha) error
+type mockRecordRiskTask struct {
+	executeFunc func(ctx context.Context, c client.Client) error
+}
+
+func (m *mockRecordRiskTask) Execute(ctx context.Context, c client.Client) error {
+	return m.executeFunc(ctx, c)
+}
+
+type mockIssueTokenTask struct {
+	executeFunc func(ctx context.Context, id, site, mode string) (string, error)
 }
 
-func (m *mockValidateCaptchaTask) Execute(ctx context.Context, id, val string, c *captcha.Captcha) error {
-	return m.executeFunc(ctx, id, val, c)
+func (m *mockIssueTokenTask) Execute(ctx context.Context, id, site, mode string) (string, error) {
+	return m.executeFunc(ctx, id, site, mode)
 }
 
 func TestProcess_Verify(t *testing.T) {
 	tests := []struct {
 		name         string
-		readFunc     func(context.Context, string) (*captcha.Captcha, error)
-		validateFunc func(context.Context, string, string, *captcha.Captcha) error
+		validateFunc func(context.Context, string, string) (string, error)
+		issueFunc    func(context.Context, string, string, string) (string, error)
 		wantErr      error
+		wantRecorded bool
 		wantId       string
+		wantToken    string
 	}{
 		{
 			name: "successful verification",
-			readFunc: func(ctx context.Context, id string) (*captcha.Captcha, error) {
-				return &captcha.Captcha{}, nil
+			validateFunc: func(ctx context.Context, id, val string) (string, error) {
+				return "site-key", nil
 			},
-			validateFunc: func(ctx context.Context, id, val string, c *captcha.Captcha) error {
-				return nil
+			issueFunc: func(ctx context.Context, id, site, mode string) (string, error) {
+				return "token-" + id + "-" + site + "-" + mode, nil
 			},
-			wantErr: nil,
-			wantId:  "test-id",
+			wantErr:   nil,
+			wantId:    "test-id",
+			wantToken: "token-test-id-site-key-challenge",
 		},
 		{
 			name: "captcha not found error",
-			readFunc: func(ctx context.Context, id string) (*captcha.Captcha, error) {
-				return nil, errors.New("not found")
-			},
-			validateFunc: func(ctx context.Context, id, val string, c *captcha.Captcha) error {
-				return nil
+			validateFunc: func(ctx context.Context, id, val string) (string, error) {
+				return "", errors.New("not found")
 			},
 			wantErr: errors.New("not found"),
 			wantId:  "",
 		},
 		{
 			name: "validation logic error",
-			readFunc: func(ctx context.Context, id string) (*captcha.Captcha, error) {
-				return &captcha.Captcha{}, nil
-			},
-			validateFunc: func(ctx context.Context, id, val string, c *captcha.Captcha) error {
-				return errors.New("invalid value")
+			validateFunc: func(ctx context.Context, id, val string) (string, error) {
+				return "", errors.New("invalid value")
 			},
 			wantErr: errors.New("invalid value"),
 			wantId:  "",
 		},
+		{
+			name: "wrong answer is recorded",
+			validateFunc: func(ctx context.Context, id, val string) (string, error) {
+				return "", appErrors.ErrInvalidCaptchaValue
+			},
+			wantErr:      appErrors.ErrInvalidCaptchaValue,
+			wantRecorded: true,
+		},
+		{
+			name: "exhausted tries are recorded",
+			validateFunc: func(ctx context.Context, id, val string) (string, error) {
+				return "", appErrors.ErrNoTriesLeft
+			},
+			wantErr:      appErrors.ErrNoTriesLeft,
+			wantRecorded: true,
+		},
+		{
+			name: "token issue error",
+			validateFunc: func(ctx context.Context, id, val string) (string, error) {
+				return "site-key", nil
+			},
+			issueFunc: func(ctx context.Context, id, site, mode string) (string, error) {
+				return "", errors.New("sign fail")
+			},
+			wantErr: errors.New("sign fail"),
+			wantId:  "",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			recorded := false
 			p := NewProcess(
-				&mockReadCaptchaTask{executeFunc: tt.readFunc},
 				&mockValidateCaptchaTask{executeFunc: tt.validateFunc},
+				&mockRecordRiskTask{executeFunc: func(ctx context.Context, c client.Client) error {
+					if c.IP != "203.0.113.7" {
+						t.Errorf("client not passed: %+v", c)
+					}
+					recorded = true
+					return errors.New("redis down")
+				}},
+				&mockIssueTokenTask{executeFunc: tt.issueFunc},
 			)
 
-			resp, err := p.Process(context.Background(), Request{CaptchaId: "test-id", CaptchaValue: "test-val"})
+			resp, err := p.Process(context.Background(), Request{CaptchaId: "test-id", CaptchaValue: "test-val", Client: client.Client{IP: "203.0.113.7"}})
+
+			if recorded != tt.wantRecorded {
+				t.Errorf("Process() recorded risk = %v, want %v", recorded, tt.wantRecorded)
+			}
 
 			if tt.wantErr != nil {
 				if err == nil || err.Error() != tt.wantErr.Error() {
@@ -90,6 +135,9 @@ func TestProcess_Verify(t *testing.T) {
 			if resp.CaptchaId != tt.wantId {
 				t.Errorf("Process() CaptchaId = %v, want %v", resp.CaptchaId, tt.wantId)
 			}
+			if resp.Token != tt.wantToken {
+				t.Errorf("Process() Token = %v, want %v", resp.Token, tt.wantToken)
+			}
 		})
 	}
 }
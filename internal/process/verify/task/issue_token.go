@@ -0,0 +1,45 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/pkg/solvetoken"
+)
+
+type IssueTokenTask struct {
+	key        []byte
+	audience   string
+	ttlSeconds int
+}
+
+func NewIssueTokenTask(key, audience string, ttlSeconds int) *IssueTokenTask {
+	return &IssueTokenTask{
+		key:        []byte(key),
+		audience:   audience,
+		ttlSeconds: ttlSeconds,
+	}
+}
+
+func (t *IssueTokenTask) Execute(ctx context.Context, id, site, mode string) (_ string, err error) {
+	_, span := tracing.Start(ctx, "verify.IssueTokenTask")
+	defer func() { tracing.End(span, err) }()
+
+	now := time.Now()
+
+	token, err := solvetoken.Sign(solvetoken.Claims{
+		CaptchaId: id,
+		Audience:  t.audience,
+		Site:      site,
+		Mode:      mode,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(time.Duration(t.ttlSeconds) * time.Second).Unix(),
+	}, t.key)
+	if err != nil {
+		return "", errors.ErrInternalServerError.Wrap(err)
+	}
+
+	return token, nil
+}
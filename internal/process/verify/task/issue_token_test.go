@@ -0,0 +1,26 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/pkg/solvetoken"
+)
+
+func TestIssueTokenTask_Execute(t *testing.T) {
+	ctx := context.Background()
+	task := NewIssueTokenTask("token-key", "contact", 60)
+
+	token, err := task.Execute(ctx, "id-1", "site-key", solvetoken.ModeInvisible)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, err := solvetoken.Verify(token, []byte("token-key"), "contact", "site-key")
+	if err != nil {
+		t.Fatalf("token does not verify: %v", err)
+	}
+	if claims.CaptchaId != "id-1" || claims.Site != "site-key" || claims.Mode != solvetoken.ModeInvisible || claims.ExpiresAt-claims.IssuedAt != 60 {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+}
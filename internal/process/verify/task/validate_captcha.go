@@ -2,55 +2,121 @@ package task
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
-	captcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/tracing"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/pkg/solvetoken"
 )
 
+// validateCaptchaScript checks the answer, decrements the tries and marks the
+// captcha solved in one step, so parallel guesses cannot see the same TriesLeft.
+const validateCaptchaScript = `
+-- validate_captcha
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return {'not_found'}
+end
+
+local captcha = cjson.decode(data)
+if captcha.solved then
+	return {'already_solved'}
+end
+
+local guess = redis.sha1hex(ARGV[1])
+local correct = redis.sha1hex(captcha.value) == guess
+if captcha.audioValue then
+	correct = correct or redis.sha1hex(captcha.audioValue) == guess
+end
+if not correct then
+	captcha.triesLeft = captcha.triesLeft - 1
+	captcha.failedAttempts = (captcha.failedAttempts or 0) + 1
+	if captcha.triesLeft <= 0 then
+		redis.call('DEL', KEYS[1])
+		return {'no_tries_left'}
+	end
+	redis.call('SET', KEYS[1], cjson.encode(captcha), 'KEEPTTL')
+	return {'invalid'}
+end
+
+if ARGV[4] == '1' then
+	redis.call('DEL', KEYS[1])
+	return {'solved', captcha.site or ''}
+end
+
+captcha.solved = true
+captcha.solvedAt = tonumber(ARGV[2])
+redis.call('SET', KEYS[1], cjson.encode(captcha), 'PX', ARGV[3])
+return {'solved', captcha.site or ''}
+`
+
 type ValidateCaptchaRedisClient interface {
-	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
-	Del(ctx context.Context, key string) error
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type SolveObserver interface {
+	ObserveCaptchaSolved(mode string)
 }
 
 type ValidateCaptchaTask struct {
-	client     ValidateCaptchaRedisClient
-	ttlMinutes int
+	client         ValidateCaptchaRedisClient
+	ttlMinutes     int
+	consumeOnSolve bool
+	observer       SolveObserver
 }
 
-func NewValidateCaptchaTask(c ValidateCaptchaRedisClient, ttl int) *ValidateCaptchaTask {
+func NewValidateCaptchaTask(c ValidateCaptchaRedisClient, ttl int, consumeOnSolve bool, observer SolveObserver) *ValidateCaptchaTask {
 	return &ValidateCaptchaTask{
-		client:     c,
-		ttlMinutes: ttl,
+		client:         c,
+		ttlMinutes:     ttl,
+		consumeOnSolve: consumeOnSolve,
+		observer:       observer,
 	}
 }
 
-func (t *ValidateCaptchaTask) Execute(ctx context.Context, id, value string, captcha *captcha.Captcha) error {
-	key := fmt.Sprintf("captcha:%s", id)
+func (t *ValidateCaptchaTask) Execute(ctx context.Context, id, value string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "verify.ValidateCaptchaTask")
+	defer func() { tracing.End(span, err) }()
 
-	if captcha.Value != value {
-		captcha.TriesLeft--
-		if captcha.TriesLeft <= 0 {
-			t.client.Del(ctx, key)
-			return errors.ErrNoTriesLeft
-		}
-
-		data, _ := json.Marshal(captcha)
-		err := t.client.Set(ctx, key, string(data), time.Duration(t.ttlMinutes)*time.Minute)
-		if err != nil {
-			return errors.ErrInternalServerError
-		}
+	key := fmt.Sprintf("captcha:%s", id)
+	ttl := time.Duration(t.ttlMinutes) * time.Minute
 
-		return errors.ErrInvalidCaptchaValue
+	consume := "0"
+	if t.consumeOnSolve {
+		consume = "1"
 	}
-	captcha.Solved = true
 
-	data, err := json.Marshal(captcha)
+	res, err := t.client.Eval(ctx, validateCaptchaScript, []string{key}, value, time.Now().Unix(), ttl.Milliseconds(), consume)
 	if err != nil {
-		return errors.ErrInternalServerError
+		return "", errors.ErrInternalServerError.Wrap(err)
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) == 0 {
+		return "", errors.ErrInternalServerError
 	}
 
-	return t.client.Set(ctx, key, string(data), time.Duration(t.ttlMinutes)*time.Minute)
+	switch values[0] {
+	case "solved":
+		if len(values) != 2 {
+			return "", errors.ErrInternalServerError
+		}
+		site, ok := values[1].(string)
+		if !ok {
+			return "", errors.ErrInternalServerError
+		}
+		t.observer.ObserveCaptchaSolved(solvetoken.ModeChallenge)
+		return site, nil
+	case "invalid":
+		return "", errors.ErrInvalidCaptchaValue
+	case "no_tries_left":
+		return "", errors.ErrNoTriesLeft
+	case "already_solved":
+		return "", errors.ErrCaptchaSolved
+	case "not_found":
+		return "", errors.ErrCaptchaNotFound
+	default:
+		return "", errors.ErrInternalServerError
+	}
 }
@@ -2,55 +2,211 @@ package task
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
-	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
+	appErrors "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/errors"
 	taskCaptcha "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/process/captcha/task/captchatest"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/pkg/solvetoken"
 )
 
 type mockValidateCaptchaRedisClient struct {
-	setFunc func(ctx context.Context, key string, value interface{}, expiration time.Duration) error
-	delFunc func(ctx context.Context, key string) error
+	evalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
 }
 
-func (m *mockValidateCaptchaRedisClient) Set(ctx context.Context, key string, v interface{}, e time.Duration) error {
-	return m.setFunc(ctx, key, v, e)
+func (m *mockValidateCaptchaRedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return m.evalFunc(ctx, script, keys, args...)
 }
-func (m *mockValidateCaptchaRedisClient) Del(ctx context.Context, key string) error {
-	return m.delFunc(ctx, key)
+
+type mockSolveObserver struct {
+	solved []string
+}
+
+func (m *mockSolveObserver) ObserveCaptchaSolved(mode string) {
+	m.solved = append(m.solved, mode)
 }
 
 func TestValidateCaptchaTask_Execute(t *testing.T) {
 	ctx := context.Background()
 
+	t.Run("result mapping", func(t *testing.T) {
+		results := map[string]error{
+			"invalid":        appErrors.ErrInvalidCaptchaValue,
+			"no_tries_left":  appErrors.ErrNoTriesLeft,
+			"not_found":      appErrors.ErrCaptchaNotFound,
+			"already_solved": appErrors.ErrCaptchaSolved,
+			"unexpected":     appErrors.ErrInternalServerError,
+		}
+		for res, want := range results {
+			m := &mockValidateCaptchaRedisClient{
+				evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+					if keys[0] != "captcha:id" || args[0] != "123" {
+						t.Errorf("unexpected call: keys=%v, args=%v", keys, args)
+					}
+					return []interface{}{res}, nil
+				},
+			}
+			task := NewValidateCaptchaTask(m, 3, false, &mockSolveObserver{})
+			if _, err := task.Execute(ctx, "id", "123"); err != want {
+				t.Errorf("result %v: expected %v, got %v", res, want, err)
+			}
+		}
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		m := &mockValidateCaptchaRedisClient{
+			evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+				return nil, errors.New("redis fail")
+			},
+		}
+		task := NewValidateCaptchaTask(m, 3, false, &mockSolveObserver{})
+		if _, err := task.Execute(ctx, "id", "123"); !errors.Is(err, appErrors.ErrInternalServerError) {
+			t.Errorf("expected ErrInternalServerError, got %v", err)
+		}
+	})
+
 	t.Run("correct value", func(t *testing.T) {
-		m := &mockValidateCaptchaRedisClient{setFunc: func(ctx context.Context, key string, v interface{}, e time.Duration) error { return nil }}
-		task := NewValidateCaptchaTask(m, 3)
-		state := &taskCaptcha.Captcha{Value: "123", Solved: false}
-		err := task.Execute(ctx, "id", "123", state)
-		if err != nil || !state.Solved {
-			t.Errorf("expected solved, got err=%v", err)
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", TriesLeft: 3, IssuedAt: 100, Site: "site-key"})
+		observer := &mockSolveObserver{}
+		task := NewValidateCaptchaTask(client, 3, false, observer)
+		site, err := task.Execute(ctx, "id", "123")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if site != "site-key" {
+			t.Errorf("site = %q, want %q", site, "site-key")
+		}
+		state := captchatest.ReadRedisCaptcha(t, mr)
+		if !state.Solved || state.SolvedAt == 0 || state.IssuedAt != 100 {
+			t.Errorf("expected solved captcha, got %+v", state)
+		}
+		if mr.TTL("captcha:id") != 3*time.Minute {
+			t.Errorf("unexpected ttl: %v", mr.TTL("captcha:id"))
+		}
+		if len(observer.solved) != 1 || observer.solved[0] != solvetoken.ModeChallenge {
+			t.Errorf("observed solves %v", observer.solved)
+		}
+	})
+
+	t.Run("audio value", func(t *testing.T) {
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "AB12CD", AudioValue: "123456", TriesLeft: 3})
+		task := NewValidateCaptchaTask(client, 3, false, &mockSolveObserver{})
+		if _, err := task.Execute(ctx, "id", "123456"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if state := captchatest.ReadRedisCaptcha(t, mr); !state.Solved {
+			t.Errorf("expected solved captcha, got %+v", state)
+		}
+	})
+
+	t.Run("correct value, consumed on solve", func(t *testing.T) {
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", TriesLeft: 3})
+		task := NewValidateCaptchaTask(client, 3, true, &mockSolveObserver{})
+		if _, err := task.Execute(ctx, "id", "123"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if mr.Exists("captcha:id") {
+			t.Error("expected captcha to be consumed")
+		}
+		if _, err := task.Execute(ctx, "id", "123"); !errors.Is(err, appErrors.ErrCaptchaNotFound) {
+			t.Errorf("expected ErrCaptchaNotFound on repeat, got %v", err)
+		}
+	})
+
+	t.Run("already solved", func(t *testing.T) {
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", TriesLeft: 3, Solved: true, SolvedAt: 50})
+		mr.SetTTL("captcha:id", time.Minute)
+		task := NewValidateCaptchaTask(client, 3, false, &mockSolveObserver{})
+		for _, value := range []string{"123", "wrong"} {
+			if _, err := task.Execute(ctx, "id", value); !errors.Is(err, appErrors.ErrCaptchaSolved) {
+				t.Errorf("value %s: expected ErrCaptchaSolved, got %v", value, err)
+			}
+		}
+		state := captchatest.ReadRedisCaptcha(t, mr)
+		if state.SolvedAt != 50 || state.TriesLeft != 3 {
+			t.Errorf("solved captcha was modified: %+v", state)
+		}
+		if mr.TTL("captcha:id") != time.Minute {
+			t.Errorf("ttl was extended: %v", mr.TTL("captcha:id"))
+		}
+	})
+
+	t.Run("prefix and extension of the answer", func(t *testing.T) {
+		_, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", TriesLeft: 5})
+		task := NewValidateCaptchaTask(client, 3, false, &mockSolveObserver{})
+		for _, value := range []string{"12", "1234", ""} {
+			if _, err := task.Execute(ctx, "id", value); !errors.Is(err, appErrors.ErrInvalidCaptchaValue) {
+				t.Errorf("value %q: expected ErrInvalidCaptchaValue, got %v", value, err)
+			}
 		}
 	})
 
 	t.Run("wrong value, tries left", func(t *testing.T) {
-		m := &mockValidateCaptchaRedisClient{setFunc: func(ctx context.Context, key string, v interface{}, e time.Duration) error { return nil }}
-		task := NewValidateCaptchaTask(m, 3)
-		state := &taskCaptcha.Captcha{Value: "123", TriesLeft: 2}
-		err := task.Execute(ctx, "id", "wrong", state)
-		if err != errors.ErrInvalidCaptchaValue || state.TriesLeft != 1 {
-			t.Errorf("wrong behavior: err=%v, tries=%d", err, state.TriesLeft)
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", TriesLeft: 2})
+		mr.SetTTL("captcha:id", time.Minute)
+		task := NewValidateCaptchaTask(client, 3, false, &mockSolveObserver{})
+		if _, err := task.Execute(ctx, "id", "wrong"); !errors.Is(err, appErrors.ErrInvalidCaptchaValue) {
+			t.Fatalf("expected ErrInvalidCaptchaValue, got %v", err)
+		}
+		state := captchatest.ReadRedisCaptcha(t, mr)
+		if state.TriesLeft != 1 || state.FailedAttempts != 1 || state.Solved {
+			t.Errorf("wrong behavior: %+v", state)
+		}
+		if mr.TTL("captcha:id") != time.Minute {
+			t.Errorf("ttl was extended: %v", mr.TTL("captcha:id"))
 		}
 	})
 
 	t.Run("wrong value, no tries left", func(t *testing.T) {
-		m := &mockValidateCaptchaRedisClient{delFunc: func(ctx context.Context, key string) error { return nil }}
-		task := NewValidateCaptchaTask(m, 3)
-		state := &taskCaptcha.Captcha{Value: "123", TriesLeft: 1}
-		err := task.Execute(ctx, "id", "wrong", state)
-		if err != errors.ErrNoTriesLeft {
-			t.Errorf("expected ErrNoTriesLeft, got %v", err)
+		mr, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", TriesLeft: 1})
+		task := NewValidateCaptchaTask(client, 3, false, &mockSolveObserver{})
+		if _, err := task.Execute(ctx, "id", "wrong"); !errors.Is(err, appErrors.ErrNoTriesLeft) {
+			t.Fatalf("expected ErrNoTriesLeft, got %v", err)
+		}
+		if mr.Exists("captcha:id") {
+			t.Error("expected captcha to be deleted")
 		}
 	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, client := captchatest.NewRedis(t)
+		task := NewValidateCaptchaTask(client, 3, false, &mockSolveObserver{})
+		if _, err := task.Execute(ctx, "id", "123"); !errors.Is(err, appErrors.ErrCaptchaNotFound) {
+			t.Errorf("expected ErrCaptchaNotFound, got %v", err)
+		}
+	})
+
+	t.Run("concurrent wrong guesses", func(t *testing.T) {
+		const maxTries = 3
+		const n = 50
+		_, client := captchatest.NewRedisCaptcha(t, taskCaptcha.Captcha{Value: "123", TriesLeft: maxTries})
+		task := NewValidateCaptchaTask(client, 3, false, &mockSolveObserver{})
+
+		results := make(chan error, n)
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := task.Execute(ctx, "id", "wrong")
+				results <- err
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		counts := map[error]int{}
+		for err := range results {
+			counts[err]++
+		}
+		if counts[appErrors.ErrInvalidCaptchaValue] != maxTries-1 ||
+			counts[appErrors.ErrNoTriesLeft] != 1 ||
+			counts[appErrors.ErrCaptchaNotFound] != n-maxTries {
+			t.Errorf("guesses exceeded max tries: %v", counts)
+		}
+	})
+
 }
@@ -1,102 +1,200 @@
 package registry
 
 import (
-	"log"
+	"flag"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
 
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
 	"gopkg.in/yaml.v3"
 )
 
+type HmacKey struct {
+	Id        string    `yaml:"id"`
+	Secret    string    `yaml:"secret"`
+	RetiredAt time.Time `yaml:"retiredAt"`
+}
+
+type SiteverifySecret struct {
+	Site   string `yaml:"site"`
+	Secret string `yaml:"secret"`
+}
+
+type RateLimitRule struct {
+	Limit         int `yaml:"limit" env:"LIMIT"`
+	WindowSeconds int `yaml:"windowSeconds" env:"WINDOW_SECONDS"`
+}
+
+type CaptchaDriver struct {
+	Type            string   `yaml:"type" env:"TYPE"`
+	Height          int      `yaml:"height" env:"HEIGHT"`
+	Width           int      `yaml:"width" env:"WIDTH"`
+	NoiseCount      int      `yaml:"noiseCount" env:"NOISE_COUNT"`
+	ShowLineOptions []string `yaml:"showLineOptions" env:"SHOW_LINE_OPTIONS"`
+	Length          int      `yaml:"length" env:"LENGTH"`
+	Source          string   `yaml:"source" env:"SOURCE"`
+	Fonts           []string `yaml:"fonts" env:"FONTS"`
+	MaxSkew         float64  `yaml:"maxSkew" env:"MAX_SKEW"`
+	DotCount        int      `yaml:"dotCount" env:"DOT_COUNT"`
+	Language        string   `yaml:"language" env:"LANGUAGE"`
+}
+
+type EscalationLevel struct {
+	Score           float64 `yaml:"score"`
+	ExtraDifficulty int     `yaml:"extraDifficulty"`
+	SeedTtlSeconds  int     `yaml:"seedTtlSeconds"`
+	HardCaptcha     bool    `yaml:"hardCaptcha"`
+}
+
 type Config struct {
 	Server struct {
-		HTTPPort string `yaml:"httpPort"`
+		HTTPPort       string   `yaml:"httpPort" env:"HTTP_PORT"`
+		MetricsPort    string   `yaml:"metricsPort" env:"METRICS_PORT"`
+		TrustedProxies []string `yaml:"trustedProxies" env:"TRUSTED_PROXIES"`
 	} `yaml:"server"`
 	Infrastructure struct {
 		Retry struct {
-			MaxAttempts  int           `yaml:"maxAttempts"`
-			DelaySeconds time.Duration `yaml:"delaySeconds"`
-		} `yaml:"retry"`
+			MaxAttempts int           `yaml:"maxAttempts" env:"MAX_ATTEMPTS"`
+			Delay       time.Duration `yaml:"delay" env:"DELAY"`
+		} `yaml:"retry" env:"RETRY"`
 	} `yaml:"infrastructure"`
+	Health struct {
+		RedisTimeout time.Duration `yaml:"redisTimeout" env:"REDIS_TIMEOUT"`
+		DrainDelay   time.Duration `yaml:"drainDelay" env:"DRAIN_DELAY"`
+	} `yaml:"health" env:"HEALTH"`
 	Redis struct {
-		URL string `yaml:"url"`
-	} `yaml:"redis"`
+		URL string `yaml:"url" env:"URL"`
+	} `yaml:"redis" env:"REDIS"`
+	Logging struct {
+		Level string `yaml:"level" env:"LEVEL"`
+	} `yaml:"logging" env:"LOG"`
+	Tracing struct {
+		Exporter    string  `yaml:"exporter" env:"EXPORTER"`
+		Endpoint    string  `yaml:"endpoint" env:"ENDPOINT"`
+		Insecure    bool    `yaml:"insecure" env:"INSECURE"`
+		SampleRatio float64 `yaml:"sampleRatio" env:"SAMPLE_RATIO"`
+		ServiceName string  `yaml:"serviceName" env:"SERVICE_NAME"`
+	} `yaml:"tracing" env:"TRACING"`
 	Security struct {
-		HmacSecret string `yaml:"hmacSecret"`
-		Difficulty int    `yaml:"difficulty"`
-		TtlMinutes int    `yaml:"ttlMinutes"`
+		HmacSecret     string    `yaml:"hmacSecret" env:"HMAC_SECRET"`
+		ActiveKeyId    string    `yaml:"activeKeyId" env:"HMAC_ACTIVE_KEY_ID"`
+		Keys           []HmacKey `yaml:"keys" env:"HMAC_KEYS"`
+		Difficulty     int       `yaml:"difficulty" env:"POW_DIFFICULTY"`
+		DifficultyMode string    `yaml:"difficultyMode" env:"POW_DIFFICULTY_MODE"`
+		TtlMinutes     int       `yaml:"ttlMinutes" env:"SEED_TTL_MINUTES"`
+		Audience       string    `yaml:"audience" env:"SEED_AUDIENCE"`
+		Adaptive       struct {
+			MaxDifficulty   int `yaml:"maxDifficulty" env:"MAX_DIFFICULTY"`
+			WindowSeconds   int `yaml:"windowSeconds" env:"WINDOW_SECONDS"`
+			RequestsPerStep int `yaml:"requestsPerStep" env:"REQUESTS_PER_STEP"`
+		} `yaml:"adaptive" env:"POW_ADAPTIVE"`
+		Algorithm struct {
+			Name     string `yaml:"name" env:"NAME"`
+			Argon2id struct {
+				Time          uint32 `yaml:"time" env:"TIME"`
+				MemoryKiB     uint32 `yaml:"memoryKiB" env:"MEMORY_KIB"`
+				Threads       uint8  `yaml:"threads" env:"THREADS"`
+				Difficulty    int    `yaml:"difficulty" env:"DIFFICULTY"`
+				MaxDifficulty int    `yaml:"maxDifficulty" env:"MAX_DIFFICULTY"`
+			} `yaml:"argon2id" env:"ARGON2ID"`
+			Scrypt struct {
+				N             int `yaml:"n" env:"N"`
+				R             int `yaml:"r" env:"R"`
+				P             int `yaml:"p" env:"P"`
+				Difficulty    int `yaml:"difficulty" env:"DIFFICULTY"`
+				MaxDifficulty int `yaml:"maxDifficulty" env:"MAX_DIFFICULTY"`
+			} `yaml:"scrypt" env:"SCRYPT"`
+		} `yaml:"algorithm" env:"POW_ALGORITHM"`
+		Binding struct {
+			Fields []string `yaml:"fields" env:"FIELDS"`
+		} `yaml:"binding" env:"SEED_BINDING"`
 	} `yaml:"security"`
 	Captcha struct {
-		TtlMinutes int `yaml:"ttlMinutes"`
-		MaxTries   int `yaml:"maxTries"`
-	} `yaml:"captcha"`
+		TtlMinutes     int           `yaml:"ttlMinutes" env:"TTL_MINUTES"`
+		MaxTries       int           `yaml:"maxTries" env:"MAX_TRIES"`
+		ConsumeOnSolve bool          `yaml:"consumeOnSolve" env:"CONSUME_ON_SOLVE"`
+		MaxRefreshes   int           `yaml:"maxRefreshes" env:"MAX_REFRESHES"`
+		InvisibleSites []string      `yaml:"invisibleSites" env:"INVISIBLE_SITES"`
+		Driver         CaptchaDriver `yaml:"driver" env:"DRIVER"`
+		Audio          struct {
+			Length   int    `yaml:"length" env:"LENGTH"`
+			Language string `yaml:"language" env:"LANGUAGE"`
+		} `yaml:"audio" env:"AUDIO"`
+	} `yaml:"captcha" env:"CAPTCHA"`
+	RateLimit struct {
+		Allowlist  []string      `yaml:"allowlist" env:"ALLOWLIST"`
+		Pow        RateLimitRule `yaml:"pow" env:"POW"`
+		Captcha    RateLimitRule `yaml:"captcha" env:"CAPTCHA"`
+		Verify     RateLimitRule `yaml:"verify" env:"VERIFY"`
+		Refresh    RateLimitRule `yaml:"refresh" env:"REFRESH"`
+		Audio      RateLimitRule `yaml:"audio" env:"AUDIO"`
+		Siteverify RateLimitRule `yaml:"siteverify" env:"SITEVERIFY"`
+	} `yaml:"rateLimit" env:"RATE_LIMIT"`
+	Escalation struct {
+		HalfLifeSeconds int               `yaml:"halfLifeSeconds" env:"HALF_LIFE_SECONDS"`
+		IPv4PrefixBits  int               `yaml:"ipv4PrefixBits" env:"IPV4_PREFIX_BITS"`
+		IPv6PrefixBits  int               `yaml:"ipv6PrefixBits" env:"IPV6_PREFIX_BITS"`
+		Levels          []EscalationLevel `yaml:"levels" env:"LEVELS"`
+		HardDriver      CaptchaDriver     `yaml:"hardDriver" env:"HARD_DRIVER"`
+	} `yaml:"escalation" env:"ESCALATION"`
+	Siteverify struct {
+		Secrets []SiteverifySecret `yaml:"secrets" env:"SECRETS"`
+	} `yaml:"siteverify" env:"SITEVERIFY"`
+	Token struct {
+		Secret     string `yaml:"secret" env:"SECRET"`
+		Audience   string `yaml:"audience" env:"AUDIENCE"`
+		TtlSeconds int    `yaml:"ttlSeconds" env:"TTL_SECONDS"`
+	} `yaml:"token" env:"TOKEN"`
 }
 
 var Cfg *Config
 
-func LoadConfig() (*Config, error) {
-	type yamlConfig struct {
-		Server struct {
-			HTTPPort string `yaml:"httpPort"`
-		} `yaml:"server"`
-		Infrastructure struct {
-			Retry struct {
-				MaxAttempts  int `yaml:"maxAttempts"`
-				DelaySeconds int `yaml:"delaySeconds"`
-			} `yaml:"retry"`
-		} `yaml:"infrastructure"`
-		Redis struct {
-			URL string `yaml:"url"`
-		} `yaml:"redis"`
-		Security struct {
-			HmacSecret string `yaml:"hmacSecret"`
-			Difficulty int    `yaml:"difficulty"`
-			TtlMinutes int    `yaml:"ttlMinutes"`
-		} `yaml:"security"`
-		Captcha struct {
-			TtlMinutes int `yaml:"ttlMinutes"`
-			MaxTries   int `yaml:"maxTries"`
-		} `yaml:"captcha"`
+func (c *Config) PowDifficulty() (base, max int) {
+	switch c.Security.Algorithm.Name {
+	case pow.AlgorithmArgon2id:
+		return c.Security.Algorithm.Argon2id.Difficulty, c.Security.Algorithm.Argon2id.MaxDifficulty
+	case pow.AlgorithmScrypt:
+		return c.Security.Algorithm.Scrypt.Difficulty, c.Security.Algorithm.Scrypt.MaxDifficulty
+	default:
+		return c.Security.Difficulty, c.Security.Adaptive.MaxDifficulty
 	}
+}
 
-	env := os.Getenv("APP_ENV")
-	if env != "production" {
-		env = "local"
+func LoadConfig(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("captcha-service", flag.ContinueOnError)
+	env := flags.String("env", os.Getenv("APP_ENV"), "runtime environment, local or production")
+	configPath := flags.String("config", os.Getenv("CONFIG_PATH"), "path of the YAML configuration (default config/<env>/config.yml)")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
 	}
-	configPath := filepath.Join("config", env, "config.yml")
-	log.Printf("INFO: loading configuration from %s", configPath)
 
-	f, err := os.Open(configPath)
-	if err != nil {
-		return nil, err
+	if *env != "production" {
+		*env = "local"
+	}
+	if *configPath == "" {
+		*configPath = filepath.Join("config", *env, "config.yml")
 	}
-	defer f.Close()
+	slog.Info("loading configuration", "path", *configPath)
 
-	var yc yamlConfig
-	if err := yaml.NewDecoder(f).Decode(&yc); err != nil {
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
 		return nil, err
 	}
 
 	cfg := &Config{}
-	cfg.Server.HTTPPort = yc.Server.HTTPPort
-	cfg.Infrastructure.Retry.MaxAttempts = yc.Infrastructure.Retry.MaxAttempts
-	cfg.Infrastructure.Retry.DelaySeconds = time.Duration(yc.Infrastructure.Retry.DelaySeconds) * time.Second
-	cfg.Redis.URL = yc.Redis.URL
-	cfg.Security.HmacSecret = yc.Security.HmacSecret
-	cfg.Security.Difficulty = yc.Security.Difficulty
-	cfg.Security.TtlMinutes = yc.Security.TtlMinutes
-	cfg.Captcha.TtlMinutes = yc.Captcha.TtlMinutes
-	cfg.Captcha.MaxTries = yc.Captcha.MaxTries
-
-	overrideFromEnv("REDIS_URL", &cfg.Redis.URL)
-	overrideFromEnv("HMAC_SECRET", &cfg.Security.HmacSecret)
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
 
-	return cfg, nil
-}
+	if err := overrideFromEnv(cfg); err != nil {
+		return nil, err
+	}
 
-func overrideFromEnv(envKey string, configValue *string) {
-	if value, exists := os.LookupEnv(envKey); exists && value != "" {
-		*configValue = value
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
+
+	return cfg, nil
 }
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Chdir("../..")
+
+	t.Run("local", func(t *testing.T) {
+		t.Setenv("APP_ENV", "local")
+		if _, err := LoadConfig(nil); err != nil {
+			t.Errorf("LoadConfig(nil) error = %v", err)
+		}
+	})
+
+	t.Run("production without secrets", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+		var validationErr *ValidationError
+		if _, err := LoadConfig(nil); !errors.As(err, &validationErr) {
+			t.Errorf("LoadConfig(nil) error = %v, want a ValidationError", err)
+		}
+	})
+
+	t.Run("config flag", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+		cfg, err := LoadConfig([]string{"-config", "config/local/config.yml"})
+		if err != nil {
+			t.Fatalf("LoadConfig() error = %v", err)
+		}
+		if cfg.Health.RedisTimeout != 500*time.Millisecond || cfg.Infrastructure.Retry.Delay != 2*time.Second {
+			t.Errorf("durations not converted: %+v, %+v", cfg.Health, cfg.Infrastructure.Retry)
+		}
+	})
+
+	t.Run("env flag", func(t *testing.T) {
+		t.Setenv("APP_ENV", "local")
+		var validationErr *ValidationError
+		if _, err := LoadConfig([]string{"-env", "production"}); !errors.As(err, &validationErr) {
+			t.Errorf("LoadConfig() error = %v, want the production ValidationError", err)
+		}
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		if _, err := LoadConfig([]string{"-port", "80"}); err == nil {
+			t.Error("expected an error for an unknown flag")
+		}
+	})
+
+	t.Run("production", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+		t.Setenv("REDIS_URL", "rediss://redis.internal:6380/0")
+		t.Setenv("HMAC_SECRET", strings.Repeat("h", minSecretLength))
+		t.Setenv("SITEVERIFY_SECRETS", "contact:"+strings.Repeat("v", minSecretLength))
+		t.Setenv("TOKEN_SECRET", strings.Repeat("t", minSecretLength))
+		if _, err := LoadConfig(nil); err != nil {
+			t.Errorf("LoadConfig(nil) error = %v", err)
+		}
+	})
+}
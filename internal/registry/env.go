@@ -0,0 +1,193 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	hmacKeysType = reflect.TypeOf([]HmacKey(nil))
+	secretsType  = reflect.TypeOf([]SiteverifySecret(nil))
+)
+
+// overrideFromEnv sets every env-tagged field from its variable or from the
+// file named by the variable with a _FILE suffix.
+func overrideFromEnv(cfg *Config) error {
+	return overrideStructFromEnv(reflect.ValueOf(cfg).Elem(), "")
+}
+
+func overrideStructFromEnv(v reflect.Value, prefix string) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		tag, tagged := field.Tag.Lookup("env")
+		name := joinEnv(prefix, tag)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := overrideStructFromEnv(v.Field(i), name); err != nil {
+				return err
+			}
+			continue
+		}
+		if !tagged {
+			continue
+		}
+
+		value, ok, err := lookupEnv(name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(i), value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func joinEnv(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if name == "" {
+		return prefix
+	}
+	return prefix + "_" + name
+}
+
+func lookupEnv(name string) (string, bool, error) {
+	value := os.Getenv(name)
+	path := os.Getenv(name + "_FILE")
+
+	switch {
+	case value != "" && path != "":
+		return "", false, fmt.Errorf("%s and %s_FILE are both set", name, name)
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("%s_FILE: %w", name, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), true, nil
+	default:
+		return value, value != "", nil
+	}
+}
+
+func setField(v reflect.Value, value string) error {
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case hmacKeysType:
+		keys, err := parseKeys(value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(keys))
+		return nil
+	case secretsType:
+		secrets, err := parseSecrets(value)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(secrets))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			v.Set(reflect.ValueOf(splitList(value)))
+			return nil
+		}
+		return yaml.Unmarshal([]byte(value), v.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseKeys reads a comma-separated list of "id:secret" entries, optionally
+// followed by ":<RFC 3339 retirement time>".
+func parseKeys(value string) ([]HmacKey, error) {
+	var keys []HmacKey
+	for _, entry := range splitList(value) {
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("expected id:secret, got %q", entry)
+		}
+		key := HmacKey{Id: parts[0], Secret: parts[1]}
+		if len(parts) == 3 {
+			retiredAt, err := time.Parse(time.RFC3339, parts[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid retirement time for key %q: %w", parts[0], err)
+			}
+			key.RetiredAt = retiredAt
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// parseSecrets reads a comma-separated list of "site:secret" entries.
+func parseSecrets(value string) ([]SiteverifySecret, error) {
+	var secrets []SiteverifySecret
+	for _, entry := range splitList(value) {
+		site, secret, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("expected site:secret, got %q", entry)
+		}
+		secrets = append(secrets, SiteverifySecret{Site: site, Secret: secret})
+	}
+
+	return secrets, nil
+}
@@ -0,0 +1,148 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverrideFromEnv(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "hmac_secret")
+	if err := os.WriteFile(secretFile, []byte("mounted-secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("REDIS_URL", "redis://redis:6379/1")
+	t.Setenv("HMAC_SECRET_FILE", secretFile)
+	t.Setenv("HTTP_PORT", "9090")
+	t.Setenv("TRUSTED_PROXIES", " 10.0.0.0/8, 192.0.2.1,")
+	t.Setenv("HEALTH_REDIS_TIMEOUT", "250ms")
+	t.Setenv("TRACING_INSECURE", "true")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	t.Setenv("POW_ALGORITHM_ARGON2ID_THREADS", "4")
+	t.Setenv("CAPTCHA_DRIVER_TYPE", "digit")
+	t.Setenv("ESCALATION_HARD_DRIVER_LENGTH", "9")
+	t.Setenv("RATE_LIMIT_VERIFY_LIMIT", "7")
+	t.Setenv("ESCALATION_LEVELS", "[{score: 2, extraDifficulty: 1, hardCaptcha: true}]")
+	t.Setenv("HMAC_KEYS", "k1:first:2024-01-02T03:04:05Z, k2:second")
+	t.Setenv("SITEVERIFY_SECRETS", "contact:first, blog:sec:ond")
+	t.Setenv("TOKEN_SECRET", "")
+
+	cfg := &Config{}
+	cfg.Token.Secret = "from-yaml"
+	if err := overrideFromEnv(cfg); err != nil {
+		t.Fatalf("overrideFromEnv() error = %v", err)
+	}
+
+	if cfg.Redis.URL != "redis://redis:6379/1" {
+		t.Errorf("Redis.URL = %q", cfg.Redis.URL)
+	}
+	if cfg.Security.HmacSecret != "mounted-secret" {
+		t.Errorf("Security.HmacSecret = %q, want the trimmed file content", cfg.Security.HmacSecret)
+	}
+	if cfg.Server.HTTPPort != "9090" || !reflect.DeepEqual(cfg.Server.TrustedProxies, []string{"10.0.0.0/8", "192.0.2.1"}) {
+		t.Errorf("Server = %+v", cfg.Server)
+	}
+	if cfg.Health.RedisTimeout != 250*time.Millisecond {
+		t.Errorf("Health.RedisTimeout = %v", cfg.Health.RedisTimeout)
+	}
+	if !cfg.Tracing.Insecure || cfg.Tracing.SampleRatio != 0.25 {
+		t.Errorf("Tracing = %+v", cfg.Tracing)
+	}
+	if cfg.Security.Algorithm.Argon2id.Threads != 4 {
+		t.Errorf("Argon2id.Threads = %d", cfg.Security.Algorithm.Argon2id.Threads)
+	}
+	if cfg.Captcha.Driver.Type != "digit" || cfg.Escalation.HardDriver.Length != 9 {
+		t.Errorf("drivers = %+v, %+v", cfg.Captcha.Driver, cfg.Escalation.HardDriver)
+	}
+	if cfg.RateLimit.Verify.Limit != 7 {
+		t.Errorf("RateLimit.Verify.Limit = %d", cfg.RateLimit.Verify.Limit)
+	}
+	if want := []EscalationLevel{{Score: 2, ExtraDifficulty: 1, HardCaptcha: true}}; !reflect.DeepEqual(cfg.Escalation.Levels, want) {
+		t.Errorf("Escalation.Levels = %+v, want %+v", cfg.Escalation.Levels, want)
+	}
+	wantKeys := []HmacKey{
+		{Id: "k1", Secret: "first", RetiredAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{Id: "k2", Secret: "second"},
+	}
+	if !reflect.DeepEqual(cfg.Security.Keys, wantKeys) {
+		t.Errorf("Security.Keys = %+v, want %+v", cfg.Security.Keys, wantKeys)
+	}
+	wantSecrets := []SiteverifySecret{{Site: "contact", Secret: "first"}, {Site: "blog", Secret: "sec:ond"}}
+	if !reflect.DeepEqual(cfg.Siteverify.Secrets, wantSecrets) {
+		t.Errorf("Siteverify.Secrets = %+v, want %+v", cfg.Siteverify.Secrets, wantSecrets)
+	}
+	if cfg.Token.Secret != "from-yaml" {
+		t.Errorf("empty variable overrode Token.Secret: %q", cfg.Token.Secret)
+	}
+}
+
+func TestOverrideFromEnv_Errors(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "invalid int", env: map[string]string{"CAPTCHA_MAX_TRIES": "three"}, wantErr: "CAPTCHA_MAX_TRIES"},
+		{name: "int out of range", env: map[string]string{"POW_ALGORITHM_ARGON2ID_THREADS": "300"}, wantErr: "POW_ALGORITHM_ARGON2ID_THREADS"},
+		{name: "duration without unit", env: map[string]string{"HEALTH_DRAIN_DELAY": "5"}, wantErr: "HEALTH_DRAIN_DELAY"},
+		{name: "invalid bool", env: map[string]string{"CAPTCHA_CONSUME_ON_SOLVE": "maybe"}, wantErr: "CAPTCHA_CONSUME_ON_SOLVE"},
+		{name: "invalid key", env: map[string]string{"HMAC_KEYS": "k1"}, wantErr: "HMAC_KEYS"},
+		{name: "siteverify secret without site", env: map[string]string{"SITEVERIFY_SECRETS": "secret"}, wantErr: "SITEVERIFY_SECRETS"},
+		{name: "missing file", env: map[string]string{"TOKEN_SECRET_FILE": "/nonexistent/secret"}, wantErr: "TOKEN_SECRET_FILE"},
+		{name: "value and file", env: map[string]string{"TOKEN_SECRET": "secret", "TOKEN_SECRET_FILE": secretFile}, wantErr: "both set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			err := overrideFromEnv(&Config{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("overrideFromEnv() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_EnvTags(t *testing.T) {
+	seen := map[string]string{}
+	var walk func(typ reflect.Type, prefix, path string)
+	walk = func(typ reflect.Type, prefix, path string) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			tag, tagged := field.Tag.Lookup("env")
+			name, fieldPath := joinEnv(prefix, tag), path+"."+field.Name
+
+			if field.Type.Kind() == reflect.Struct {
+				walk(field.Type, name, fieldPath)
+				continue
+			}
+			if !tagged || tag == "" {
+				t.Errorf("%s has no env tag", fieldPath)
+				continue
+			}
+			if other, ok := seen[name]; ok {
+				t.Errorf("%s and %s share the variable %s", other, fieldPath, name)
+			}
+			seen[name] = fieldPath
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "", "Config")
+
+	for _, name := range []string{"REDIS_URL", "HMAC_SECRET", "HMAC_KEYS", "HMAC_ACTIVE_KEY_ID", "TOKEN_SECRET", "SITEVERIFY_SECRETS", "TRUSTED_PROXIES", "RATE_LIMIT_ALLOWLIST", "LOG_LEVEL", "TRACING_EXPORTER", "TRACING_ENDPOINT"} {
+		if _, ok := seen[name]; !ok {
+			t.Errorf("variable %s is no longer supported", name)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package registry
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/audio"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/pow"
+)
+
+const (
+	minSecretLength = 32
+	// maxDifficultyBits keeps the expected PoW work within seconds in a browser.
+	maxDifficultyBits = 32
+	// maxMemoryHardDifficultyBits is the same bound for argon2id and scrypt.
+	maxMemoryHardDifficultyBits = 10
+)
+
+// ValidationError lists every invalid setting found by Validate.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+func (c *Config) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	checkDriver := func(field string, d CaptchaDriver) {
+		switch d.Type {
+		case "", "string", "math", "digit":
+			check(d.Height > 0 && d.Width > 0, "%s.height and %s.width must be positive", field, field)
+			check(d.Type == "math" || d.Length >= 1, "%s.length must be at least 1", field)
+		case "audio":
+			check(d.Length >= 1, "%s.length must be at least 1", field)
+			check(audio.Languages[d.Language], "%s.language %q is not supported", field, d.Language)
+		default:
+			problems = append(problems, fmt.Sprintf("%s.type must be string, math, digit or audio, got %q", field, d.Type))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.HTTPPort)
+	check(err == nil && port >= 1 && port <= 65535, "server.httpPort must be a port number between 1 and 65535, got %q", c.Server.HTTPPort)
+	metricsPort, err := strconv.Atoi(c.Server.MetricsPort)
+	check(err == nil && metricsPort >= 1 && metricsPort <= 65535, "server.metricsPort must be a port number between 1 and 65535, got %q", c.Server.MetricsPort)
+	check(c.Server.MetricsPort != c.Server.HTTPPort, "server.metricsPort must differ from server.httpPort")
+	for _, p := range c.Server.TrustedProxies {
+		check(validNetwork(p), "server.trustedProxies: %q is not an IP address or CIDR", p)
+	}
+
+	check(c.Infrastructure.Retry.MaxAttempts >= 1, "infrastructure.retry.maxAttempts must be at least 1")
+	check(c.Infrastructure.Retry.Delay >= 0, "infrastructure.retry.delay must not be negative")
+	check(c.Health.RedisTimeout > 0, "health.redisTimeout must be positive")
+	check(c.Health.DrainDelay >= 0, "health.drainDelay must not be negative")
+
+	redisURL, err := url.Parse(c.Redis.URL)
+	check(err == nil && (redisURL.Scheme == "redis" || redisURL.Scheme == "rediss"), "redis.url must be a redis:// or rediss:// URL")
+
+	var level slog.Level
+	check(c.Logging.Level == "" || level.UnmarshalText([]byte(c.Logging.Level)) == nil, "logging.level must be debug, info, warn or error, got %q", c.Logging.Level)
+
+	switch c.Tracing.Exporter {
+	case "", "none":
+	case "stdout", "otlp":
+		check(c.Tracing.ServiceName != "", "tracing.serviceName is required when traces are exported")
+	default:
+		problems = append(problems, fmt.Sprintf("tracing.exporter must be none, stdout or otlp, got %q", c.Tracing.Exporter))
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio must be between 0 and 1")
+
+	if len(c.Security.Keys) == 0 {
+		check(len(c.Security.HmacSecret) >= minSecretLength, "security.hmacSecret must be at least %d characters", minSecretLength)
+	} else {
+		active := false
+		for _, k := range c.Security.Keys {
+			check(k.Id != "" && !strings.Contains(k.Id, "."), "security.keys: key id %q must be non-empty and must not contain '.'", k.Id)
+			check(len(k.Secret) >= minSecretLength, "security.keys: secret of key %q must be at least %d characters", k.Id, minSecretLength)
+			active = active || (k.Id == c.Security.ActiveKeyId && k.RetiredAt.IsZero())
+		}
+		check(active, "security.activeKeyId %q must name a key in security.keys that is not retired", c.Security.ActiveKeyId)
+	}
+
+	modeOk := c.Security.DifficultyMode == pow.ModeHex || c.Security.DifficultyMode == pow.ModeBits
+	check(modeOk, "security.difficultyMode must be %s or %s, got %q", pow.ModeHex, pow.ModeBits, c.Security.DifficultyMode)
+	escalated := false
+	for _, l := range c.Escalation.Levels {
+		escalated = escalated || l.ExtraDifficulty > 0
+	}
+	checkDifficulty := func(field, maxField string, base, max, limit int) {
+		bits := pow.Difficulty{Value: base, Mode: c.Security.DifficultyMode}.Bits()
+		check(bits >= 1 && bits <= limit, "%s must require between 1 and %d bits of work, got %d", field, limit, bits)
+		if c.Security.Adaptive.RequestsPerStep > 0 || escalated {
+			maxBits := pow.Difficulty{Value: max, Mode: c.Security.DifficultyMode}.Bits()
+			check(max >= base && maxBits <= limit, "%s must be between %s and %d bits of work", maxField, field, limit)
+		}
+	}
+	if modeOk {
+		checkDifficulty("security.difficulty", "security.adaptive.maxDifficulty", c.Security.Difficulty, c.Security.Adaptive.MaxDifficulty, maxDifficultyBits)
+		switch c.Security.Algorithm.Name {
+		case pow.AlgorithmArgon2id:
+			a := c.Security.Algorithm.Argon2id
+			checkDifficulty("security.algorithm.argon2id.difficulty", "security.algorithm.argon2id.maxDifficulty", a.Difficulty, a.MaxDifficulty, maxMemoryHardDifficultyBits)
+		case pow.AlgorithmScrypt:
+			s := c.Security.Algorithm.Scrypt
+			checkDifficulty("security.algorithm.scrypt.difficulty", "security.algorithm.scrypt.maxDifficulty", s.Difficulty, s.MaxDifficulty, maxMemoryHardDifficultyBits)
+		}
+	}
+	if c.Security.Adaptive.RequestsPerStep > 0 {
+		check(c.Security.Adaptive.WindowSeconds > 0, "security.adaptive.windowSeconds must be positive")
+	}
+	check(c.Security.TtlMinutes > 0, "security.ttlMinutes must be positive")
+	check(c.Security.Audience != "", "security.audience must not be empty")
+
+	check(c.Captcha.TtlMinutes > 0, "captcha.ttlMinutes must be positive")
+	// Used seeds are remembered for captcha.ttlMinutes, so a seed must expire
+	// before its double-spend record does.
+	check(c.Captcha.TtlMinutes >= c.Security.TtlMinutes, "captcha.ttlMinutes (%d) must be at least security.ttlMinutes (%d), or seeds can be spent twice", c.Captcha.TtlMinutes, c.Security.TtlMinutes)
+	check(c.Captcha.MaxTries >= 1, "captcha.maxTries must be at least 1")
+	check(c.Captcha.MaxRefreshes >= 0, "captcha.maxRefreshes must not be negative")
+	checkDriver("captcha.driver", c.Captcha.Driver)
+	if c.Escalation.HardDriver.Type != "" {
+		checkDriver("escalation.hardDriver", c.Escalation.HardDriver)
+	}
+	check(c.Captcha.Audio.Length >= 1, "captcha.audio.length must be at least 1")
+	check(audio.Languages[c.Captcha.Audio.Language], "captcha.audio.language %q is not supported", c.Captcha.Audio.Language)
+
+	for _, a := range c.RateLimit.Allowlist {
+		check(validNetwork(a), "rateLimit.allowlist: %q is not an IP address or CIDR", a)
+	}
+	rules := []struct {
+		name string
+		rule RateLimitRule
+	}{
+		{"pow", c.RateLimit.Pow},
+		{"captcha", c.RateLimit.Captcha},
+		{"verify", c.RateLimit.Verify},
+		{"refresh", c.RateLimit.Refresh},
+		{"audio", c.RateLimit.Audio},
+		{"siteverify", c.RateLimit.Siteverify},
+	}
+	for _, r := range rules {
+		check(r.rule.Limit <= 0 || r.rule.WindowSeconds > 0, "rateLimit.%s.windowSeconds must be positive when a limit is set", r.name)
+	}
+
+	for _, l := range c.Escalation.Levels {
+		check(l.SeedTtlSeconds <= c.Security.TtlMinutes*60, "escalation.levels: seedTtlSeconds of the level at score %g must not exceed security.ttlMinutes", l.Score)
+		check(l.ExtraDifficulty >= 0, "escalation.levels: extraDifficulty of the level at score %g must not be negative", l.Score)
+	}
+
+	check(len(c.Siteverify.Secrets) > 0, "siteverify.secrets must not be empty")
+	seen := make(map[string]bool, len(c.Siteverify.Secrets))
+	for i, s := range c.Siteverify.Secrets {
+		check(s.Site != "", "siteverify.secrets[%d].site must not be empty", i)
+		check(len(s.Secret) >= minSecretLength, "siteverify.secrets[%d].secret must be at least %d characters", i, minSecretLength)
+		check(!seen[s.Secret], "siteverify.secrets[%d].secret is shared with another site", i)
+		seen[s.Secret] = true
+	}
+
+	check(len(c.Token.Secret) >= minSecretLength, "token.secret must be at least %d characters", minSecretLength)
+	separate := c.Token.Secret != c.Security.HmacSecret
+	for _, k := range c.Security.Keys {
+		separate = separate && c.Token.Secret != k.Secret
+	}
+	check(separate, "token.secret must differ from the seed signing secrets")
+	check(c.Token.Audience != "", "token.audience is required")
+	check(c.Token.TtlSeconds > 0, "token.ttlSeconds must be positive")
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func validNetwork(s string) bool {
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return true
+	}
+	return net.ParseIP(s) != nil
+}
@@ -0,0 +1,205 @@
+package registry
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func validConfig() *Config {
+	cfg := &Config{}
+	cfg.Server.HTTPPort = "8083"
+	cfg.Server.MetricsPort = "9090"
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "192.0.2.1"}
+	cfg.Infrastructure.Retry.MaxAttempts = 5
+	cfg.Infrastructure.Retry.Delay = 2 * time.Second
+	cfg.Health.RedisTimeout = 500 * time.Millisecond
+	cfg.Redis.URL = "redis://:password@localhost:6379/0"
+	cfg.Logging.Level = "info"
+	cfg.Tracing.Exporter = "stdout"
+	cfg.Tracing.SampleRatio = 1
+	cfg.Tracing.ServiceName = "captcha-service"
+	cfg.Security.HmacSecret = strings.Repeat("s", minSecretLength)
+	cfg.Security.Difficulty = 16
+	cfg.Security.DifficultyMode = "bits"
+	cfg.Security.TtlMinutes = 3
+	cfg.Security.Audience = "adrianjanczenia.dev"
+	cfg.Security.Adaptive.MaxDifficulty = 22
+	cfg.Security.Adaptive.WindowSeconds = 60
+	cfg.Security.Adaptive.RequestsPerStep = 300
+	cfg.Security.Algorithm.Argon2id.Difficulty = 4
+	cfg.Security.Algorithm.Argon2id.MaxDifficulty = 8
+	cfg.Security.Algorithm.Scrypt.Difficulty = 4
+	cfg.Security.Algorithm.Scrypt.MaxDifficulty = 8
+	cfg.Captcha.TtlMinutes = 3
+	cfg.Captcha.MaxTries = 3
+	cfg.Captcha.MaxRefreshes = 3
+	cfg.Captcha.Driver = CaptchaDriver{Type: "string", Height: 80, Width: 240, Length: 6}
+	cfg.Captcha.Audio.Length = 6
+	cfg.Captcha.Audio.Language = "en"
+	cfg.RateLimit.Pow = RateLimitRule{Limit: 30, WindowSeconds: 60}
+	cfg.Escalation.Levels = []EscalationLevel{{Score: 3, ExtraDifficulty: 2, SeedTtlSeconds: 120}}
+	cfg.Siteverify.Secrets = []SiteverifySecret{{Site: "contact", Secret: strings.Repeat("v", minSecretLength)}}
+	cfg.Token.Secret = strings.Repeat("t", minSecretLength)
+	cfg.Token.Audience = "adrianjanczenia.dev"
+	cfg.Token.TtlSeconds = 120
+	return cfg
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(c *Config)
+		wantProblem string
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{name: "tracing disabled without service name", modify: func(c *Config) { c.Tracing.Exporter = "none"; c.Tracing.ServiceName = "" }},
+		{
+			name: "adaptive and escalation disabled",
+			modify: func(c *Config) {
+				c.Security.Adaptive.RequestsPerStep = 0
+				c.Security.Adaptive.MaxDifficulty = 0
+				c.Escalation.Levels = nil
+			},
+		},
+		{name: "rate limit disabled", modify: func(c *Config) { c.RateLimit.Verify = RateLimitRule{} }},
+		{
+			name: "keyring",
+			modify: func(c *Config) {
+				c.Security.HmacSecret = ""
+				c.Security.ActiveKeyId = "k2"
+				c.Security.Keys = []HmacKey{
+					{Id: "k1", Secret: strings.Repeat("a", minSecretLength), RetiredAt: time.Now()},
+					{Id: "k2", Secret: strings.Repeat("b", minSecretLength)},
+				}
+			},
+		},
+		{name: "non-numeric port", modify: func(c *Config) { c.Server.HTTPPort = "http" }, wantProblem: "server.httpPort"},
+		{name: "port out of range", modify: func(c *Config) { c.Server.HTTPPort = "70000" }, wantProblem: "server.httpPort"},
+		{name: "non-numeric metrics port", modify: func(c *Config) { c.Server.MetricsPort = "" }, wantProblem: "server.metricsPort"},
+		{name: "metrics port shared with http", modify: func(c *Config) { c.Server.MetricsPort = "8083" }, wantProblem: "server.metricsPort must differ"},
+		{name: "invalid trusted proxy", modify: func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/33"} }, wantProblem: "server.trustedProxies"},
+		{name: "no retry attempts", modify: func(c *Config) { c.Infrastructure.Retry.MaxAttempts = 0 }, wantProblem: "infrastructure.retry.maxAttempts"},
+		{name: "no redis timeout", modify: func(c *Config) { c.Health.RedisTimeout = 0 }, wantProblem: "health.redisTimeout"},
+		{name: "missing redis url", modify: func(c *Config) { c.Redis.URL = "" }, wantProblem: "redis.url"},
+		{name: "unknown log level", modify: func(c *Config) { c.Logging.Level = "verbose" }, wantProblem: "logging.level"},
+		{name: "unknown exporter", modify: func(c *Config) { c.Tracing.Exporter = "jaeger" }, wantProblem: "tracing.exporter"},
+		{name: "sample ratio above one", modify: func(c *Config) { c.Tracing.SampleRatio = 1.5 }, wantProblem: "tracing.sampleRatio"},
+		{name: "empty hmac secret", modify: func(c *Config) { c.Security.HmacSecret = "" }, wantProblem: "security.hmacSecret"},
+		{name: "short hmac secret", modify: func(c *Config) { c.Security.HmacSecret = "secret" }, wantProblem: "security.hmacSecret"},
+		{
+			name: "retired active key",
+			modify: func(c *Config) {
+				c.Security.ActiveKeyId = "k1"
+				c.Security.Keys = []HmacKey{{Id: "k1", Secret: strings.Repeat("a", minSecretLength), RetiredAt: time.Now()}}
+			},
+			wantProblem: "security.activeKeyId",
+		},
+		{
+			name: "key id with separator",
+			modify: func(c *Config) {
+				c.Security.ActiveKeyId = "k.1"
+				c.Security.Keys = []HmacKey{{Id: "k.1", Secret: strings.Repeat("a", minSecretLength)}}
+			},
+			wantProblem: "must not contain '.'",
+		},
+		{name: "unknown difficulty mode", modify: func(c *Config) { c.Security.DifficultyMode = "bytes" }, wantProblem: "security.difficultyMode"},
+		{name: "zero difficulty", modify: func(c *Config) { c.Security.Difficulty = 0 }, wantProblem: "security.difficulty"},
+		{name: "hex difficulty too high", modify: func(c *Config) { c.Security.DifficultyMode = "hex"; c.Security.Difficulty = 9 }, wantProblem: "security.difficulty"},
+		{name: "adaptive maximum below base", modify: func(c *Config) { c.Security.Adaptive.MaxDifficulty = 12 }, wantProblem: "security.adaptive.maxDifficulty"},
+		{
+			name: "escalation without maximum",
+			modify: func(c *Config) {
+				c.Security.Adaptive.RequestsPerStep = 0
+				c.Security.Adaptive.MaxDifficulty = 0
+			},
+			wantProblem: "security.adaptive.maxDifficulty",
+		},
+		{name: "argon2id difficulty", modify: func(c *Config) { c.Security.Algorithm.Name = "argon2id" }},
+		{
+			name:        "argon2id with the sha256 difficulty",
+			modify:      func(c *Config) { c.Security.Algorithm.Name = "argon2id"; c.Security.Algorithm.Argon2id.Difficulty = 16 },
+			wantProblem: "security.algorithm.argon2id.difficulty",
+		},
+		{
+			name:        "scrypt adaptive maximum too high",
+			modify:      func(c *Config) { c.Security.Algorithm.Name = "scrypt"; c.Security.Algorithm.Scrypt.MaxDifficulty = 22 },
+			wantProblem: "security.algorithm.scrypt.maxDifficulty",
+		},
+		{name: "empty seed audience", modify: func(c *Config) { c.Security.Audience = "" }, wantProblem: "security.audience"},
+		{name: "zero seed ttl", modify: func(c *Config) { c.Security.TtlMinutes = 0 }, wantProblem: "security.ttlMinutes"},
+		{name: "seed outlives captcha", modify: func(c *Config) { c.Security.TtlMinutes = 5 }, wantProblem: "seeds can be spent twice"},
+		{name: "zero tries", modify: func(c *Config) { c.Captcha.MaxTries = 0 }, wantProblem: "captcha.maxTries"},
+		{name: "unknown driver", modify: func(c *Config) { c.Captcha.Driver.Type = "emoji" }, wantProblem: "captcha.driver.type"},
+		{name: "string driver without length", modify: func(c *Config) { c.Captcha.Driver.Length = 0 }, wantProblem: "captcha.driver.length"},
+		{name: "math driver without size", modify: func(c *Config) { c.Captcha.Driver = CaptchaDriver{Type: "math"} }, wantProblem: "captcha.driver.height"},
+		{name: "math driver", modify: func(c *Config) { c.Captcha.Driver.Type = "math"; c.Captcha.Driver.Length = 0 }},
+		{name: "digit driver without length", modify: func(c *Config) { c.Captcha.Driver.Type = "digit"; c.Captcha.Driver.Length = 0 }, wantProblem: "captcha.driver.length"},
+		{name: "audio driver", modify: func(c *Config) { c.Captcha.Driver = CaptchaDriver{Type: "audio", Length: 6, Language: "en"} }},
+		{name: "audio driver language", modify: func(c *Config) { c.Captcha.Driver = CaptchaDriver{Type: "audio", Length: 6, Language: "xx"} }, wantProblem: "captcha.driver.language"},
+		{
+			name:        "hard driver without size",
+			modify:      func(c *Config) { c.Escalation.HardDriver = CaptchaDriver{Type: "digit", Length: 6} },
+			wantProblem: "escalation.hardDriver.height",
+		},
+		{name: "unsupported audio language", modify: func(c *Config) { c.Captcha.Audio.Language = "xx" }, wantProblem: "captcha.audio.language"},
+		{name: "rate limit without window", modify: func(c *Config) { c.RateLimit.Pow.WindowSeconds = 0 }, wantProblem: "rateLimit.pow.windowSeconds"},
+		{name: "siteverify rate limit without window", modify: func(c *Config) { c.RateLimit.Siteverify.Limit = 100 }, wantProblem: "rateLimit.siteverify.windowSeconds"},
+		{name: "escalated seed ttl too long", modify: func(c *Config) { c.Escalation.Levels[0].SeedTtlSeconds = 600 }, wantProblem: "seedTtlSeconds"},
+		{name: "no siteverify secrets", modify: func(c *Config) { c.Siteverify.Secrets = nil }, wantProblem: "siteverify.secrets"},
+		{name: "siteverify secret without site", modify: func(c *Config) { c.Siteverify.Secrets[0].Site = "" }, wantProblem: "siteverify.secrets[0].site"},
+		{name: "short siteverify secret", modify: func(c *Config) { c.Siteverify.Secrets[0].Secret = "secret" }, wantProblem: "siteverify.secrets[0].secret"},
+		{
+			name: "siteverify secret shared between sites",
+			modify: func(c *Config) {
+				c.Siteverify.Secrets = append(c.Siteverify.Secrets, SiteverifySecret{Site: "blog", Secret: c.Siteverify.Secrets[0].Secret})
+			},
+			wantProblem: "shared with another site",
+		},
+		{name: "short token secret", modify: func(c *Config) { c.Token.Secret = "secret" }, wantProblem: "token.secret"},
+		{name: "token secret reuses hmac secret", modify: func(c *Config) { c.Token.Secret = c.Security.HmacSecret }, wantProblem: "token.secret"},
+		{
+			name: "token secret reuses keyring secret",
+			modify: func(c *Config) {
+				c.Security.ActiveKeyId = "k1"
+				c.Security.Keys = []HmacKey{{Id: "k1", Secret: c.Token.Secret}}
+			},
+			wantProblem: "token.secret",
+		},
+		{name: "zero token ttl", modify: func(c *Config) { c.Token.TtlSeconds = 0 }, wantProblem: "token.ttlSeconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.wantProblem == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantProblem) {
+				t.Errorf("Validate() error = %v, want a problem mentioning %q", err, tt.wantProblem)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate_ReportsAllProblems(t *testing.T) {
+	cfg := validConfig()
+	cfg.Security.HmacSecret = ""
+	cfg.Security.Difficulty = 0
+	cfg.Captcha.MaxTries = 0
+
+	var validationErr *ValidationError
+	if err := cfg.Validate(); !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() error = %v, want a ValidationError", err)
+	}
+	if len(validationErr.Problems) != 3 {
+		t.Errorf("got %d problems, want 3: %v", len(validationErr.Problems), validationErr.Problems)
+	}
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/logic/requestid"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "info")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceId, SpanID: spanId}))
+	ctx = requestid.NewContext(ctx, "req-1")
+	logger.With("component", "test").InfoContext(ctx, "hello", "key", "value")
+	logger.DebugContext(ctx, "hidden")
+	logger.Info("no request")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("got %d records, want 2: %s", len(lines), buf.String())
+	}
+
+	var first map[string]any
+	if err := json.Unmarshal(lines[0], &first); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if first["msg"] != "hello" || first["request_id"] != "req-1" || first["component"] != "test" || first["key"] != "value" ||
+		first["trace_id"] != traceId.String() || first["span_id"] != spanId.String() {
+		t.Errorf("unexpected record: %v", first)
+	}
+
+	var second map[string]any
+	if err := json.Unmarshal(lines[1], &second); err != nil {
+		t.Fatalf("record is not JSON: %v", err)
+	}
+	if _, ok := second["request_id"]; ok {
+		t.Errorf("record outside a request has a request id: %v", second)
+	}
+	if _, ok := second["trace_id"]; ok {
+		t.Errorf("record outside a span has a trace id: %v", second)
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	logger.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("default level logged a debug record: %s", buf.String())
+	}
+
+	if _, err := New(&buf, "loud"); err == nil {
+		t.Error("expected error for an unknown level")
+	}
+}
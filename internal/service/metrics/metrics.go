@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "captcha"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests          *prometheus.CounterVec
+	errors            *prometheus.CounterVec
+	inFlight          *prometheus.GaugeVec
+	captchaGeneration prometheus.Histogram
+	powVerification   prometheus.Histogram
+	redisDuration     *prometheus.HistogramVec
+	redisErrors       *prometheus.CounterVec
+	generated         *prometheus.CounterVec
+	solved            *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by endpoint and status code.",
+		}, []string{"endpoint", "code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_errors_total",
+			Help:      "Error responses by endpoint and error slug.",
+		}, []string{"endpoint", "slug"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_in_flight_requests",
+			Help:      "Requests currently being served by endpoint.",
+		}, []string{"endpoint"}),
+		captchaGeneration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "generation_duration_seconds",
+			Help:      "Time spent rendering a captcha.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+		}),
+		powVerification: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "pow_verification_duration_seconds",
+			Help:      "Time spent verifying a proof of work.",
+			Buckets:   prometheus.ExponentialBuckets(0.00005, 2, 18),
+		}),
+		redisDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "redis_command_duration_seconds",
+			Help:      "Redis command latency by command.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 14),
+		}, []string{"command"}),
+		redisErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "redis_errors_total",
+			Help:      "Failed Redis commands by command.",
+		}, []string{"command"}),
+		generated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "generated_total",
+			Help:      "Captchas issued through /captcha by mode.",
+		}, []string{"mode"}),
+		solved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "solved_total",
+			Help:      "Captchas solved by mode.",
+		}, []string{"mode"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.errors,
+		m.inFlight,
+		m.captchaGeneration,
+		m.powVerification,
+		m.redisDuration,
+		m.redisErrors,
+		m.generated,
+		m.solved,
+	)
+
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) RequestStarted(endpoint string) {
+	m.inFlight.WithLabelValues(endpoint).Inc()
+}
+
+func (m *Metrics) RequestFinished(endpoint string, status int, slug string) {
+	m.inFlight.WithLabelValues(endpoint).Dec()
+	m.requests.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+	if slug != "" {
+		m.errors.WithLabelValues(endpoint, slug).Inc()
+	}
+}
+
+func (m *Metrics) ObserveCaptchaGeneration(d time.Duration) {
+	m.captchaGeneration.Observe(d.Seconds())
+}
+
+func (m *Metrics) ObserveCaptchaGenerated(mode string) {
+	m.generated.WithLabelValues(mode).Inc()
+}
+
+func (m *Metrics) ObserveCaptchaSolved(mode string) {
+	m.solved.WithLabelValues(mode).Inc()
+}
+
+func (m *Metrics) ObservePowVerification(d time.Duration) {
+	m.powVerification.Observe(d.Seconds())
+}
+
+func (m *Metrics) ObserveCommand(command string, d time.Duration, err error) {
+	m.redisDuration.WithLabelValues(command).Observe(d.Seconds())
+	if err != nil {
+		m.redisErrors.WithLabelValues(command).Inc()
+	}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("scrape status = %d", rr.Code)
+	}
+	body, _ := io.ReadAll(rr.Body)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	m := New()
+
+	m.RequestStarted("captcha")
+	m.RequestStarted("captcha")
+	m.RequestFinished("captcha", http.StatusOK, "")
+	m.RequestStarted("verify")
+	m.RequestFinished("verify", http.StatusBadRequest, "error_captcha_value")
+	m.RequestStarted("verify")
+	m.RequestFinished("verify", http.StatusOK, "")
+	m.ObserveCaptchaGeneration(3 * time.Millisecond)
+	m.ObserveCaptchaGeneration(4 * time.Millisecond)
+	m.ObservePowVerification(time.Millisecond)
+	m.ObserveCommand("get", time.Millisecond, nil)
+	m.ObserveCommand("eval", time.Millisecond, errors.New("fail"))
+	m.ObserveCaptchaGenerated("challenge")
+	m.ObserveCaptchaGenerated("challenge")
+	m.ObserveCaptchaSolved("challenge")
+	m.ObserveCaptchaGenerated("invisible")
+	m.ObserveCaptchaSolved("invisible")
+
+	out := scrape(t, m)
+
+	for _, want := range []string{
+		`captcha_http_requests_total{code="200",endpoint="captcha"} 1`,
+		`captcha_http_requests_total{code="400",endpoint="verify"} 1`,
+		`captcha_http_errors_total{endpoint="verify",slug="error_captcha_value"} 1`,
+		`captcha_http_in_flight_requests{endpoint="captcha"} 1`,
+		`captcha_http_in_flight_requests{endpoint="verify"} 0`,
+		`captcha_generation_duration_seconds_count 2`,
+		`captcha_pow_verification_duration_seconds_count 1`,
+		`captcha_redis_command_duration_seconds_count{command="get"} 1`,
+		`captcha_redis_errors_total{command="eval"} 1`,
+		`captcha_generated_total{mode="challenge"} 2`,
+		`captcha_generated_total{mode="invisible"} 1`,
+		`captcha_solved_total{mode="challenge"} 1`,
+		`captcha_solved_total{mode="invisible"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scrape is missing %q", want)
+		}
+	}
+	if strings.Contains(out, `captcha_redis_errors_total{command="get"}`) {
+		t.Error("successful command counted as an error")
+	}
+}
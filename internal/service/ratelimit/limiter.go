@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Limiter counts requests per key in a sliding window.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+type Fallback struct {
+	primary  Limiter
+	fallback Limiter
+}
+
+func NewFallback(primary, fallback Limiter) *Fallback {
+	return &Fallback{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (f *Fallback) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	allowed, retryAfter, err := f.primary.Allow(ctx, key, limit, window)
+	if err == nil {
+		return allowed, retryAfter, nil
+	}
+
+	slog.WarnContext(ctx, "rate limiter failed, using in-memory fallback", "error", err)
+	return f.fallback.Allow(ctx, key, limit, window)
+}
+
+func evaluate(prev, curr int64, elapsed, window time.Duration, limit int) (bool, time.Duration) {
+	remaining := window - elapsed
+	weighted := float64(prev)*float64(remaining)/float64(window) + float64(curr)
+	if weighted+1 <= float64(limit) {
+		return true, 0
+	}
+
+	room := float64(limit - 1)
+	if float64(curr) <= room && prev > 0 {
+		overlap := (room - float64(curr)) * float64(window) / float64(prev)
+		return false, remaining - time.Duration(overlap)
+	}
+
+	wait := remaining
+	if curr > 0 && float64(curr) > room {
+		wait += time.Duration((1 - room/float64(curr)) * float64(window))
+	}
+	return false, wait
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockLimiter struct {
+	allowFunc func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+func (m *mockLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	return m.allowFunc(ctx, key, limit, window)
+}
+
+func TestEvaluate(t *testing.T) {
+	window := time.Minute
+
+	tests := []struct {
+		name          string
+		prev, curr    int64
+		elapsed       time.Duration
+		limit         int
+		wantAllowed   bool
+		wantRetryFrom time.Duration
+		wantRetryTo   time.Duration
+	}{
+		{name: "empty", limit: 10, wantAllowed: true},
+		{name: "last slot in current window", curr: 9, limit: 10, wantAllowed: true},
+		{name: "current window full", curr: 10, elapsed: 15 * time.Second, limit: 10, wantRetryFrom: 50 * time.Second, wantRetryTo: 51 * time.Second},
+		{name: "previous window still weighs", prev: 20, curr: 0, elapsed: 30 * time.Second, limit: 10, wantRetryFrom: 3 * time.Second, wantRetryTo: 3 * time.Second},
+		{name: "previous window slid out", prev: 10, curr: 0, elapsed: 55 * time.Second, limit: 10, wantAllowed: true},
+		{name: "zero limit", limit: 0, elapsed: 20 * time.Second, wantRetryFrom: 40 * time.Second, wantRetryTo: 40 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, retryAfter := evaluate(tt.prev, tt.curr, tt.elapsed, window, tt.limit)
+			if allowed != tt.wantAllowed {
+				t.Fatalf("allowed = %v, want %v", allowed, tt.wantAllowed)
+			}
+			if retryAfter < tt.wantRetryFrom || retryAfter > tt.wantRetryTo {
+				t.Errorf("retryAfter = %v, want between %v and %v", retryAfter, tt.wantRetryFrom, tt.wantRetryTo)
+			}
+		})
+	}
+}
+
+func TestFallback_Allow(t *testing.T) {
+	ctx := context.Background()
+	fallbackCalled := false
+	fallback := &mockLimiter{allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+		fallbackCalled = true
+		return false, time.Second, nil
+	}}
+
+	t.Run("primary answers", func(t *testing.T) {
+		fallbackCalled = false
+		primary := &mockLimiter{allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+			return true, 0, nil
+		}}
+		allowed, _, err := NewFallback(primary, fallback).Allow(ctx, "k", 1, time.Minute)
+		if !allowed || err != nil || fallbackCalled {
+			t.Errorf("unexpected result: allowed=%v err=%v fallback=%v", allowed, err, fallbackCalled)
+		}
+	})
+
+	t.Run("primary fails", func(t *testing.T) {
+		fallbackCalled = false
+		primary := &mockLimiter{allowFunc: func(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+			return false, 0, errors.New("redis down")
+		}}
+		allowed, retryAfter, err := NewFallback(primary, fallback).Allow(ctx, "k", 1, time.Minute)
+		if allowed || retryAfter != time.Second || err != nil || !fallbackCalled {
+			t.Errorf("unexpected result: allowed=%v retry=%v err=%v fallback=%v", allowed, retryAfter, err, fallbackCalled)
+		}
+	})
+}
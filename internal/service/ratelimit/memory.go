@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type counter struct {
+	index  int64
+	window time.Duration
+	prev   int64
+	curr   int64
+}
+
+type Memory struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		counters: make(map[string]*counter),
+		now:      time.Now,
+	}
+}
+
+func (m *Memory) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := m.now()
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() - index*int64(window))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	c, ok := m.counters[key]
+	if !ok {
+		c = &counter{index: index, window: window}
+		m.counters[key] = c
+	}
+	switch {
+	case c.index == index-1:
+		c.prev, c.curr = c.curr, 0
+	case c.index < index-1:
+		c.prev, c.curr = 0, 0
+	}
+	c.index, c.window = index, window
+
+	allowed, retryAfter := evaluate(c.prev, c.curr, elapsed, window, limit)
+	if allowed {
+		c.curr++
+	}
+
+	return allowed, retryAfter, nil
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Minute {
+		return
+	}
+	m.lastSweep = now
+
+	for key, c := range m.counters {
+		if now.UnixNano()/int64(c.window) > c.index+1 {
+			delete(m.counters, key)
+		}
+	}
+}
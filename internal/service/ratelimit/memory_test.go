@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory_Allow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000040, 0)
+	m := NewMemory()
+	m.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if allowed, _, _ := m.Allow(ctx, "ip", 3, time.Minute); !allowed {
+			t.Fatalf("request %d rejected", i)
+		}
+	}
+
+	allowed, retryAfter, err := m.Allow(ctx, "ip", 3, time.Minute)
+	if allowed || err != nil || retryAfter <= 0 {
+		t.Fatalf("expected rejection with retry, got allowed=%v retry=%v err=%v", allowed, retryAfter, err)
+	}
+
+	if allowed, _, _ := m.Allow(ctx, "other-ip", 3, time.Minute); !allowed {
+		t.Error("keys should be limited independently")
+	}
+
+	now = now.Add(retryAfter)
+	if allowed, _, _ := m.Allow(ctx, "ip", 3, time.Minute); !allowed {
+		t.Errorf("expected request to fit after %v", retryAfter)
+	}
+
+	now = now.Add(10 * time.Minute)
+	m.Allow(ctx, "fresh", 3, time.Minute)
+	if _, ok := m.counters["ip"]; ok {
+		t.Error("idle counter should be swept")
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// slidingWindowScript only counts requests that fit, so rejected ones do not
+// extend a client's penalty. ARGV[1] weighs the previous window in ppm.
+const slidingWindowScript = `
+-- sliding_window
+local prev = tonumber(redis.call('GET', KEYS[1]) or '0')
+local curr = tonumber(redis.call('GET', KEYS[2]) or '0')
+if prev * tonumber(ARGV[1]) / 1000000 + curr + 1 <= tonumber(ARGV[2]) then
+	redis.call('INCR', KEYS[2])
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+	return {1, prev, curr}
+end
+return {0, prev, curr}
+`
+
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type Redis struct {
+	client RedisClient
+	now    func() time.Time
+}
+
+func NewRedis(c RedisClient) *Redis {
+	return &Redis{
+		client: c,
+		now:    time.Now,
+	}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	now := r.now()
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() - index*int64(window))
+	weight := int64(window-elapsed) * 1000000 / int64(window)
+
+	keys := []string{
+		fmt.Sprintf("ratelimit:%s:%d", key, index-1),
+		fmt.Sprintf("ratelimit:%s:%d", key, index),
+	}
+
+	res, err := r.client.Eval(ctx, slidingWindowScript, keys, weight, limit, (2 * window).Milliseconds())
+	if err != nil {
+		return false, 0, err
+	}
+
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 3 {
+		return false, 0, fmt.Errorf("ratelimit: unexpected script result %v", res)
+	}
+	allowed, _ := values[0].(int64)
+	prev, _ := values[1].(int64)
+	curr, _ := values[2].(int64)
+
+	if allowed == 1 {
+		return true, 0, nil
+	}
+
+	_, retryAfter := evaluate(prev, curr, elapsed, window, limit)
+	return false, retryAfter, nil
+}
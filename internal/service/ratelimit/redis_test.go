@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	serviceRedis "github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/redis"
+	"github.com/alicebob/miniredis/v2"
+)
+
+type mockRedisClient struct {
+	evalFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+func (m *mockRedisClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return m.evalFunc(ctx, script, keys, args...)
+}
+
+func TestRedis_Allow(t *testing.T) {
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	client, err := serviceRedis.NewClient("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	now := time.Unix(1700000040, 0)
+	limiter := NewRedis(client)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if allowed, _, err := limiter.Allow(ctx, "pow:1.2.3.4", 2, time.Minute); !allowed || err != nil {
+			t.Fatalf("request %d rejected: %v", i, err)
+		}
+	}
+
+	allowed, retryAfter, err := limiter.Allow(ctx, "pow:1.2.3.4", 2, time.Minute)
+	if allowed || err != nil || retryAfter <= 0 {
+		t.Fatalf("expected rejection with retry, got allowed=%v retry=%v err=%v", allowed, retryAfter, err)
+	}
+
+	key := "ratelimit:pow:1.2.3.4:28333334"
+	if got, _ := mr.Get(key); got != "2" {
+		t.Errorf("rejected request should not be counted, counter = %s", got)
+	}
+	if ttl := mr.TTL(key); ttl != 2*time.Minute {
+		t.Errorf("unexpected ttl: %v", ttl)
+	}
+
+	now = now.Add(retryAfter)
+	if allowed, _, _ := limiter.Allow(ctx, "pow:1.2.3.4", 2, time.Minute); !allowed {
+		t.Errorf("expected request to fit after %v", retryAfter)
+	}
+}
+
+func TestRedis_AllowError(t *testing.T) {
+	client := &mockRedisClient{evalFunc: func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+		return nil, errors.New("redis down")
+	}}
+
+	if _, _, err := NewRedis(client).Allow(context.Background(), "k", 1, time.Minute); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -9,9 +9,11 @@ import (
 
 type Client interface {
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
 	Del(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) (bool, error)
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
 	Ping(ctx context.Context) error
 }
 
@@ -34,6 +36,10 @@ func (c *client) Set(ctx context.Context, key string, value interface{}, expirat
 	return c.rdb.Set(ctx, key, value, expiration).Err()
 }
 
+func (c *client) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (c *client) Get(ctx context.Context, key string) (string, error) {
 	return c.rdb.Get(ctx, key).Result()
 }
@@ -50,6 +56,10 @@ func (c *client) Exists(ctx context.Context, key string) (bool, error) {
 	return val > 0, nil
 }
 
+func (c *client) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	return c.rdb.Eval(ctx, script, keys, args...).Result()
+}
+
 func (c *client) Ping(ctx context.Context) error {
 	return c.rdb.Ping(ctx).Err()
 }
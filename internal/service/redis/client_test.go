@@ -3,9 +3,12 @@ package redis
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis"
 	"github.com/go-redis/redismock/v8"
 )
@@ -57,6 +60,57 @@ func TestClient_Set(t *testing.T) {
 	})
 }
 
+func TestClient_SetNX(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	client := &client{rdb: db}
+	ctx := context.Background()
+	key := "test-key"
+	val := "test-val"
+	ttl := time.Minute
+
+	t.Run("set", func(t *testing.T) {
+		mock.ExpectSetNX(key, val, ttl).SetVal(true)
+		ok, err := client.SetNX(ctx, key, val, ttl)
+		if err != nil || !ok {
+			t.Errorf("expected ok=true, err=nil; got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("already set", func(t *testing.T) {
+		mock.ExpectSetNX(key, val, ttl).SetVal(false)
+		ok, err := client.SetNX(ctx, key, val, ttl)
+		if err != nil || ok {
+			t.Errorf("expected ok=false, err=nil; got %v, %v", ok, err)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		mr := miniredis.RunT(t)
+		c, err := NewClient("redis://" + mr.Addr())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		const n = 50
+		var wg sync.WaitGroup
+		var won int32
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if ok, err := c.SetNX(ctx, key, val, ttl); err == nil && ok {
+					atomic.AddInt32(&won, 1)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if won != 1 {
+			t.Errorf("expected exactly one SetNX to succeed, got %d", won)
+		}
+	})
+}
+
 func TestClient_Get(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	client := &client{rdb: db}
@@ -120,3 +174,27 @@ func TestClient_Exists(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_Eval(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	client := &client{rdb: db}
+	ctx := context.Background()
+	script := "return redis.call('GET', KEYS[1])"
+	keys := []string{"test-key"}
+
+	t.Run("success", func(t *testing.T) {
+		mock.ExpectEval(script, keys, "arg").SetVal("val")
+		res, err := client.Eval(ctx, script, keys, "arg")
+		if err != nil || res != "val" {
+			t.Errorf("expected val, nil; got %v, %v", res, err)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mock.ExpectEval(script, keys, "arg").SetErr(errors.New("script error"))
+		_, err := client.Eval(ctx, script, keys, "arg")
+		if err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
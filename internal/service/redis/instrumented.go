@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type CommandObserver interface {
+	ObserveCommand(command string, duration time.Duration, err error)
+}
+
+type instrumentedClient struct {
+	next     Client
+	observer CommandObserver
+}
+
+func NewInstrumentedClient(next Client, observer CommandObserver) Client {
+	return &instrumentedClient{
+		next:     next,
+		observer: observer,
+	}
+}
+
+func (c *instrumentedClient) observe(command string, start time.Time, err error) {
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+	c.observer.ObserveCommand(command, time.Since(start), err)
+}
+
+func (c *instrumentedClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	start := time.Now()
+	err := c.next.Set(ctx, key, value, expiration)
+	c.observe("set", start, err)
+	return err
+}
+
+func (c *instrumentedClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	start := time.Now()
+	ok, err := c.next.SetNX(ctx, key, value, expiration)
+	c.observe("setnx", start, err)
+	return ok, err
+}
+
+func (c *instrumentedClient) Get(ctx context.Context, key string) (string, error) {
+	start := time.Now()
+	val, err := c.next.Get(ctx, key)
+	c.observe("get", start, err)
+	return val, err
+}
+
+func (c *instrumentedClient) Del(ctx context.Context, key string) error {
+	start := time.Now()
+	err := c.next.Del(ctx, key)
+	c.observe("del", start, err)
+	return err
+}
+
+func (c *instrumentedClient) Exists(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
+	ok, err := c.next.Exists(ctx, key)
+	c.observe("exists", start, err)
+	return ok, err
+}
+
+func (c *instrumentedClient) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	start := time.Now()
+	res, err := c.next.Eval(ctx, script, keys, args...)
+	c.observe(scriptName(script), start, err)
+	return res, err
+}
+
+func (c *instrumentedClient) Ping(ctx context.Context) error {
+	start := time.Now()
+	err := c.next.Ping(ctx)
+	c.observe("ping", start, err)
+	return err
+}
+
+// scriptName labels a Lua script by its "-- <name>" header line.
+func scriptName(script string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(script), "\n")
+	if name, ok := strings.CutPrefix(line, "-- "); ok && name != "" {
+		return strings.TrimSpace(name)
+	}
+	return "eval"
+}
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+type observedCommand struct {
+	command string
+	err     error
+}
+
+type mockCommandObserver struct {
+	observed []observedCommand
+}
+
+func (m *mockCommandObserver) ObserveCommand(command string, duration time.Duration, err error) {
+	m.observed = append(m.observed, observedCommand{command: command, err: err})
+}
+
+func TestInstrumentedClient(t *testing.T) {
+	mr := miniredis.RunT(t)
+	next, err := NewClient("redis://" + mr.Addr())
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	observer := &mockCommandObserver{}
+	c := NewInstrumentedClient(next, observer)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if val, err := c.Get(ctx, "k"); err != nil || val != "v" {
+		t.Fatalf("Get() = %q, %v", val, err)
+	}
+	if _, err := c.Get(ctx, "missing"); err == nil {
+		t.Fatal("Get() on a missing key must still return the error")
+	}
+	if _, err := c.Eval(ctx, "return redis.call('NOPE')", nil); err == nil {
+		t.Fatal("Eval() expected error")
+	}
+	if _, err := c.Eval(ctx, "\n-- read_key\nreturn redis.call('GET', KEYS[1])", []string{"k"}); err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+
+	want := []struct {
+		command string
+		failed  bool
+	}{
+		{"set", false},
+		{"get", false},
+		{"get", false},
+		{"eval", true},
+		{"read_key", false},
+	}
+	if len(observer.observed) != len(want) {
+		t.Fatalf("observed %d commands, want %d", len(observer.observed), len(want))
+	}
+	for i, w := range want {
+		got := observer.observed[i]
+		if got.command != w.command || (got.err != nil) != w.failed {
+			t.Errorf("observed[%d] = %+v, want command %s failed %v", i, got, w.command, w.failed)
+		}
+	}
+}
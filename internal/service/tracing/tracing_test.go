@@ -0,0 +1,54 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestNew(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	defer otel.SetTextMapPropagator(otel.GetTextMapPropagator())
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "default exporter", opts: Options{SampleRatio: 1}},
+		{name: "none", opts: Options{Exporter: ExporterNone, SampleRatio: 1}},
+		{name: "stdout", opts: Options{Exporter: ExporterStdout, SampleRatio: 1, Writer: io.Discard}},
+		{name: "otlp", opts: Options{Exporter: ExporterOTLP, Endpoint: "localhost:4318", Insecure: true, SampleRatio: 1}},
+		{name: "unknown exporter", opts: Options{Exporter: "jaeger"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := New(context.Background(), tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			_, span := otel.Tracer("test").Start(context.Background(), "span")
+			if !span.SpanContext().IsSampled() {
+				t.Error("span is not sampled")
+			}
+			span.End()
+
+			if fields := otel.GetTextMapPropagator().Fields(); !slices.Contains(fields, "traceparent") {
+				t.Errorf("propagator fields = %v, want traceparent", fields)
+			}
+			if tt.opts.Exporter != ExporterOTLP {
+				if err := p.Shutdown(context.Background()); err != nil {
+					t.Errorf("Shutdown() error = %v", err)
+				}
+			}
+		})
+	}
+}
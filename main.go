@@ -3,7 +3,8 @@ package main
 import (
 	"context"
 	"errors"
-	"log"
+	"flag"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,34 +13,57 @@ import (
 
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/app"
 	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/registry"
+	"github.com/AdrianJanczenia/adrianjanczenia.dev_captcha-service/internal/service/logging"
 )
 
 func main() {
-	cfg, err := registry.LoadConfig()
+	logger, _ := logging.New(os.Stdout, "")
+	slog.SetDefault(logger)
+
+	cfg, err := registry.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
 	if err != nil {
-		log.Fatalf("FATAL: could not load configuration: %v", err)
+		fatal("could not load configuration", err)
 	}
 	registry.Cfg = cfg
 
+	logger, err = logging.New(os.Stdout, cfg.Logging.Level)
+	if err != nil {
+		fatal("could not configure logging", err)
+	}
+	slog.SetDefault(logger)
+
 	application, err := app.Build(registry.Cfg)
 	if err != nil {
-		log.Fatalf("FATAL: could not build application: %v", err)
+		fatal("could not build application", err)
 	}
 
 	go func() {
 		if err := application.RunHTTP(); !errors.Is(err, http.ErrServerClosed) {
-			log.Printf("ERROR: HTTP server failed: %v", err)
+			slog.Error("http server failed", "error", err)
+		}
+	}()
+	go func() {
+		if err := application.RunMetrics(); !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics server failed", "error", err)
 		}
 	}()
 
 	shutdownChannel := make(chan os.Signal, 1)
 	signal.Notify(shutdownChannel, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-shutdownChannel
-	log.Printf("INFO: received signal %s. Shutting down...", sig)
+	slog.Info("received signal, shutting down", "signal", sig.String())
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	application.Shutdown(ctx)
-	log.Println("INFO: application shutdown complete.")
+	slog.Info("application shutdown complete")
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
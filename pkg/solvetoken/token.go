@@ -0,0 +1,129 @@
+// Package solvetoken issues and verifies the HS256 JWTs returned for solved captchas.
+package solvetoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMalformed        = errors.New("solvetoken: malformed token")
+	ErrInvalidSignature = errors.New("solvetoken: invalid signature")
+	ErrExpired          = errors.New("solvetoken: token expired")
+	ErrInvalidAudience  = errors.New("solvetoken: invalid audience")
+	ErrInvalidSite      = errors.New("solvetoken: invalid site")
+)
+
+const (
+	ModeChallenge = "challenge"
+	ModeInvisible = "invisible"
+)
+
+type Claims struct {
+	CaptchaId string `json:"sub"`
+	Audience  string `json:"aud"`
+	Site      string `json:"site,omitempty"`
+	Mode      string `json:"mode"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+var encodedHeader = mustEncode(header{Alg: "HS256", Typ: "JWT"})
+
+func Sign(claims Claims, key []byte) (string, error) {
+	payload, err := encode(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := encodedHeader + "." + payload
+
+	return unsigned + "." + sign(unsigned, key), nil
+}
+
+// Verify checks the signature, expiry, audience and site of token. A valid
+// token can be presented again until exp; redeem the captcha through
+// /siteverify when a solve must count only once.
+func Verify(token string, key []byte, audience, site string) (*Claims, error) {
+	return verifyAt(token, key, audience, site, time.Now())
+}
+
+func verifyAt(token string, key []byte, audience, site string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var h header
+	if err := decode(parts[0], &h); err != nil {
+		return nil, ErrMalformed
+	}
+	if h.Alg != "HS256" {
+		return nil, ErrMalformed
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	expected, _ := base64.RawURLEncoding.DecodeString(sign(parts[0]+"."+parts[1], key))
+	if !hmac.Equal(signature, expected) {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims Claims
+	if err := decode(parts[1], &claims); err != nil {
+		return nil, ErrMalformed
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, ErrExpired
+	}
+	if audience == "" || claims.Audience != audience {
+		return nil, ErrInvalidAudience
+	}
+	if claims.Site != site {
+		return nil, ErrInvalidSite
+	}
+
+	return &claims, nil
+}
+
+func sign(unsigned string, key []byte) string {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func encode(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func mustEncode(v interface{}) string {
+	s, err := encode(v)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func decode(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,75 @@
+package solvetoken
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key := []byte("token-key")
+	now := time.Unix(1700000000, 0)
+	claims := Claims{CaptchaId: "id-1", Audience: "contact", Site: "site-key", Mode: ModeChallenge, IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()}
+
+	token, err := Sign(claims, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		got, err := verifyAt(token, key, "contact", "site-key", now)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *got != claims {
+			t.Errorf("got %+v, want %+v", got, claims)
+		}
+	})
+
+	t.Run("empty audience", func(t *testing.T) {
+		if _, err := verifyAt(token, key, "", "site-key", now); err != ErrInvalidAudience {
+			t.Errorf("expected ErrInvalidAudience, got %v", err)
+		}
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		if _, err := verifyAt(token, key, "blog", "site-key", now); err != ErrInvalidAudience {
+			t.Errorf("expected ErrInvalidAudience, got %v", err)
+		}
+	})
+
+	t.Run("wrong site", func(t *testing.T) {
+		if _, err := verifyAt(token, key, "contact", "other-site", now); err != ErrInvalidSite {
+			t.Errorf("expected ErrInvalidSite, got %v", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		if _, err := verifyAt(token, key, "contact", "site-key", now.Add(time.Minute)); err != ErrExpired {
+			t.Errorf("expected ErrExpired, got %v", err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		if _, err := verifyAt(token, []byte("other-key"), "contact", "site-key", now); err != ErrInvalidSignature {
+			t.Errorf("expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		forged, _ := Sign(Claims{CaptchaId: "id-2", Audience: "contact", ExpiresAt: claims.ExpiresAt}, []byte("other-key"))
+		parts := strings.Split(token, ".")
+		parts[1] = strings.Split(forged, ".")[1]
+		if _, err := verifyAt(strings.Join(parts, "."), key, "contact", "site-key", now); err != ErrInvalidSignature {
+			t.Errorf("expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, tok := range []string{"", "a.b", "a.b.c", token + ".extra"} {
+			if _, err := verifyAt(tok, key, "contact", "site-key", now); err != ErrMalformed {
+				t.Errorf("token %q: expected ErrMalformed, got %v", tok, err)
+			}
+		}
+	})
+}